@@ -0,0 +1,5 @@
+// Package api holds the server interfaces and models generated
+// from the OpenAPI specification in api.yaml.
+package api
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.8.0 --config=oapi-codegen.yaml ../../api.yaml
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat/memcatstore"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/log"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// TestServer_RoutesMatchSpec fails when the /v1 routes served by the Server
+// and the operations declared in api.yaml drift apart.
+func TestServer_RoutesMatchSpec(t *testing.T) {
+	spec, err := openapi3.NewLoader().LoadFromFile("../api.yaml")
+	td.CmpNoError(t, err)
+
+	var specRoutes []string
+
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			specRoutes = append(specRoutes, method+" /v1"+path)
+		}
+	}
+
+	transport := cat.NewTransport(cat.NewService(memcatstore.New()), log.DisabledLogger())
+	server := NewServer(":0", log.DisabledLogger(), transport)
+
+	var routerRoutes []string
+
+	err = chi.Walk(server.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = strings.ReplaceAll(strings.TrimSuffix(route, "/"), "/*/", "/")
+		if strings.HasPrefix(route, "/v1/") {
+			routerRoutes = append(routerRoutes, method+" "+route)
+		}
+
+		return nil
+	})
+	td.CmpNoError(t, err)
+
+	sort.Strings(specRoutes)
+	sort.Strings(routerRoutes)
+
+	td.Cmp(t, routerRoutes, specRoutes)
+}
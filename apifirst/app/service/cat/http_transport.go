@@ -0,0 +1,186 @@
+package cat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/apifirst/app/api"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/log"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/xerr"
+	"github.com/go-chi/chi/v5"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ api.StrictServerInterface = (*Transport)(nil)
+	_ chi.Routes                = (*Transport)(nil)
+)
+
+// Transport represents an HTTP transport for interaction with the Service logic.
+// Routes, request decoding and response encoding are generated from api.yaml,
+// Transport implements only the generated api.StrictServerInterface.
+type Transport struct {
+	router chi.Router
+	log    log.Logger
+
+	service Service
+}
+
+// NewTransport returns a pointer to a new instance of Transport.
+func NewTransport(service Service, logger log.Logger) *Transport {
+	t := Transport{
+		router:  chi.NewRouter(),
+		log:     logger,
+		service: service,
+	}
+
+	// Initialize routes generated from the specification.
+	api.HandlerFromMux(api.NewStrictHandlerWithOptions(&t, nil, api.StrictHTTPServerOptions{
+		RequestErrorHandlerFunc:  t.requestErrorHandler,
+		ResponseErrorHandlerFunc: t.responseErrorHandler,
+	}), t.router)
+
+	return &t
+}
+
+func (t *Transport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.router.ServeHTTP(w, r)
+}
+
+// Routes returns the registered routes. Routes, Middlewares and Match implement chi.Routes,
+// so the routes are walked through the router the Transport is mounted on, see chi.Walk.
+func (t *Transport) Routes() []chi.Route { return t.router.Routes() }
+
+// Middlewares returns the middlewares of the router.
+func (t *Transport) Middlewares() chi.Middlewares { return t.router.Middlewares() }
+
+// Match reports whether the router has a route for the method and the path.
+func (t *Transport) Match(rctx *chi.Context, method, path string) bool {
+	return t.router.Match(rctx, method, path)
+}
+
+func (t *Transport) ListCats(ctx context.Context, req api.ListCatsRequestObject) (api.ListCatsResponseObject, error) {
+	var limit, offset uint32
+
+	if req.Params.Limit != nil {
+		limit = *req.Params.Limit
+	}
+
+	if req.Params.Offset != nil {
+		offset = *req.Params.Offset
+	}
+
+	cats, err := t.service.ListCats(ctx, limit, offset)
+	if err != nil {
+		t.log.Errorf("Failed to list cats: %s", err.Error())
+
+		status, body := toErrorResponse(err)
+
+		return api.ListCatsdefaultJSONResponse{StatusCode: status, Body: body}, nil
+	}
+
+	resp := make(api.ListCats200JSONResponse, 0, len(cats))
+	for _, c := range cats {
+		resp = append(resp, toAPICat(c))
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) CreateCat(ctx context.Context, req api.CreateCatRequestObject) (api.CreateCatResponseObject, error) {
+	cat, err := t.service.CreateCat(ctx, req.Body.Name, req.Body.Breed, req.Body.Age)
+	if err != nil {
+		t.log.Errorf("Failed to create cat: %s", err.Error())
+
+		status, body := toErrorResponse(err)
+
+		return api.CreateCatdefaultJSONResponse{StatusCode: status, Body: body}, nil
+	}
+
+	return api.CreateCat201JSONResponse(toAPICat(cat)), nil
+}
+
+func (t *Transport) GetCat(ctx context.Context, req api.GetCatRequestObject) (api.GetCatResponseObject, error) {
+	cat, err := t.service.GetCatByID(ctx, req.Id)
+	if err != nil {
+		t.log.Errorf("Failed to get cat with '%s' id: %s", req.Id, err.Error())
+
+		status, body := toErrorResponse(err)
+
+		return api.GetCatdefaultJSONResponse{StatusCode: status, Body: body}, nil
+	}
+
+	return api.GetCat200JSONResponse(toAPICat(cat)), nil
+}
+
+func (t *Transport) UpdateCat(ctx context.Context, req api.UpdateCatRequestObject) (api.UpdateCatResponseObject, error) {
+	cat, err := t.service.UpdateCat(ctx, req.Id, req.Body.Name, req.Body.Breed, req.Body.Age)
+	if err != nil {
+		t.log.Errorf("Failed to update cat with '%s' id: %s", req.Id, err.Error())
+
+		status, body := toErrorResponse(err)
+
+		return api.UpdateCatdefaultJSONResponse{StatusCode: status, Body: body}, nil
+	}
+
+	return api.UpdateCat200JSONResponse(toAPICat(cat)), nil
+}
+
+func (t *Transport) DeleteCat(ctx context.Context, req api.DeleteCatRequestObject) (api.DeleteCatResponseObject, error) {
+	if err := t.service.DeleteCat(ctx, req.Id); err != nil {
+		t.log.Errorf("Failed to delete cat with '%s' id: %s", req.Id, err.Error())
+
+		status, body := toErrorResponse(err)
+
+		return api.DeleteCatdefaultJSONResponse{StatusCode: status, Body: body}, nil
+	}
+
+	return api.DeleteCat204Response{}, nil
+}
+
+func (t *Transport) requestErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
+	t.log.Errorf("Failed to decode request: %s", err.Error())
+
+	writeError(w, http.StatusBadRequest)
+}
+
+func (t *Transport) responseErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
+	t.log.Errorf("Failed to encode response: %s", err.Error())
+
+	writeError(w, http.StatusInternalServerError)
+}
+
+func toAPICat(c *Cat) api.Cat {
+	return api.Cat{
+		Id:    c.ID,
+		Name:  c.Name,
+		Breed: c.Breed,
+		Age:   c.Age,
+	}
+}
+
+// toErrorResponse maps xerr errors to an HTTP status code and the api.Error body.
+// Unknown errors are hidden behind http.StatusInternalServerError.
+func toErrorResponse(err error) (int, api.Error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, xerr.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, xerr.ErrAlreadyExists):
+		status = http.StatusConflict
+	}
+
+	return status, api.Error{Message: http.StatusText(status)}
+}
+
+func writeError(w http.ResponseWriter, status int) {
+	resp := api.ErrorJSONResponse{Message: http.StatusText(status)}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(&resp) //nolint: errcheck
+}
@@ -0,0 +1,100 @@
+package cat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/apifirst/app/api"
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat/memcatstore"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/log"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// TestTransport_ResponsesMatchSpec validates requests and responses
+// of every operation against api.yaml.
+func TestTransport_ResponsesMatchSpec(t *testing.T) {
+	spec, err := api.GetSwagger()
+	td.CmpNoError(t, err)
+
+	// Validate against paths without the server prefix.
+	spec.Servers = nil
+
+	router, err := legacy.NewRouter(spec)
+	td.CmpNoError(t, err)
+
+	transport := cat.NewTransport(cat.NewService(memcatstore.New()), log.DisabledLogger())
+
+	server := httptest.NewServer(transport)
+	t.Cleanup(func() { server.Close() })
+
+	do := func(t *testing.T, method, path string, payload any, wantStatus int) []byte {
+		t.Helper()
+
+		var body []byte
+		if payload != nil {
+			b, err := json.Marshal(payload)
+			td.CmpNoError(t, err)
+
+			body = b
+		}
+
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL+path, bytes.NewReader(body))
+		td.CmpNoError(t, err)
+
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		td.CmpNoError(t, err)
+
+		defer res.Body.Close()
+
+		resBody, err := io.ReadAll(res.Body)
+		td.CmpNoError(t, err)
+		td.Cmp(t, res.StatusCode, wantStatus)
+
+		// Validate the request and the response against the spec.
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		route, pathParams, err := router.FindRoute(req)
+		td.CmpNoError(t, err)
+
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		}
+		td.CmpNoError(t, openapi3filter.ValidateRequest(context.Background(), reqInput))
+
+		resInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 res.StatusCode,
+			Header:                 res.Header,
+			Body:                   io.NopCloser(bytes.NewReader(resBody)),
+		}
+		td.CmpNoError(t, openapi3filter.ValidateResponse(context.Background(), resInput))
+
+		return resBody
+	}
+
+	newCat := api.NewCat{Name: "test", Breed: "test-breed", Age: 10}
+
+	var created api.Cat
+	td.CmpNoError(t, json.Unmarshal(do(t, http.MethodPost, "/cat", newCat, http.StatusCreated), &created))
+	td.Cmp(t, created, td.SStruct(api.Cat{Name: "test", Breed: "test-breed", Age: 10}, td.StructFields{"Id": td.NotEmpty()}))
+
+	do(t, http.MethodGet, "/cat/"+created.Id, nil, http.StatusOK)
+	do(t, http.MethodGet, "/cat?limit=10&offset=0", nil, http.StatusOK)
+	do(t, http.MethodPut, "/cat/"+created.Id, api.NewCat{Name: "renamed", Breed: "test-breed", Age: 11}, http.StatusOK)
+	do(t, http.MethodDelete, "/cat/"+created.Id, nil, http.StatusNoContent)
+	do(t, http.MethodGet, "/cat/"+created.Id, nil, http.StatusNotFound)
+}
@@ -0,0 +1,92 @@
+// Package memcatstore implements cat.Storage interface in memory.
+// Useful for local runs and tests where Postgres is not available.
+package memcatstore
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/xerr"
+)
+
+// Storage implements cat.Storage interface using a map guarded by a mutex.
+type Storage struct {
+	mu   sync.RWMutex
+	cats map[string]cat.Cat
+}
+
+// New returns a pointer to a new instance of Storage struct.
+func New() *Storage { return &Storage{cats: make(map[string]cat.Cat)} }
+
+func (s *Storage) GetCatByID(_ context.Context, id string) (*cat.Cat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.cats[id]
+	if !ok {
+		return nil, xerr.ErrNotFound
+	}
+
+	return &c, nil
+}
+
+func (s *Storage) ListCats(_ context.Context, limit, offset uint32) ([]*cat.Cat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.cats))
+	for id := range s.cats {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	cats := make([]*cat.Cat, 0, limit)
+	for i := int(offset); i < len(ids) && len(cats) < int(limit); i++ {
+		c := s.cats[ids[i]]
+		cats = append(cats, &c)
+	}
+
+	return cats, nil
+}
+
+func (s *Storage) SaveCat(_ context.Context, c *cat.Cat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cats[c.ID]; ok {
+		return xerr.ErrAlreadyExists
+	}
+
+	s.cats[c.ID] = *c
+
+	return nil
+}
+
+func (s *Storage) UpdateCat(_ context.Context, c *cat.Cat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cats[c.ID]; !ok {
+		return xerr.ErrNotFound
+	}
+
+	s.cats[c.ID] = *c
+
+	return nil
+}
+
+func (s *Storage) DeleteCat(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cats[id]; !ok {
+		return xerr.ErrNotFound
+	}
+
+	delete(s.cats, id)
+
+	return nil
+}
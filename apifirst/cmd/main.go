@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/apifirst/app"
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/apifirst/app/service/cat/memcatstore"
+	"github.com/KitRUM/golang-blueprint/apifirst/pkg/log"
+	"github.com/urfave/cli/v2"
+)
+
+// Variables which are related to Version command.
+// Should be specified by '-ldflags' during the build phase.
+// Example:
+// GOOS=linux GOARCH=amd64 go build -ldflags="-X main.Branch=$BRANCH \
+// -X main.Commit=$COMMIT -o api.
+var (
+	// Branch is the branch this binary built from.
+	Branch = "local"
+
+	// Commit is the commit this binary built from.
+	Commit = "unknown"
+
+	// BuildTime is the time this binary built.
+	BuildTime = time.Now().Format(time.RFC822)
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cmd := &cli.App{
+		Name:  "app",
+		Usage: "root command of the app",
+		Before: func(c *cli.Context) error {
+			// Prints the app version.
+			fmt.Printf("Branch: %s, Commit: %s, Build time: %s\n\n", Branch, Commit, BuildTime)
+
+			return nil
+		},
+
+		Commands: []*cli.Command{
+			ServeCommand(),
+		},
+	}
+
+	if err := cmd.RunContext(ctx, os.Args); err != nil {
+		panic(fmt.Sprintf("Error: %s\n", err.Error()))
+	}
+}
+
+func ServeCommand() *cli.Command {
+	cfg := struct {
+		HTTPAddr string
+	}{}
+
+	command := cli.Command{
+		Name:  "serve",
+		Usage: "runs HTTP listener to serve the incoming connections",
+		Action: func(c *cli.Context) error {
+			logger := log.New() // Init logger.
+
+			catStorage := memcatstore.New()
+			catService := cat.NewService(catStorage)
+			catTransport := cat.NewTransport(catService, logger)
+
+			server := app.NewServer(cfg.HTTPAddr, logger, catTransport)
+
+			return server.Serve(c.Context)
+		},
+
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "http-addr",
+				Usage:       "defines HTTP listener address",
+				EnvVars:     []string{"HTTP_ADDR"},
+				Destination: &cfg.HTTPAddr,
+				Value:       ":8080",
+			},
+		},
+	}
+
+	return &command
+}
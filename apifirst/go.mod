@@ -0,0 +1,36 @@
+module github.com/KitRUM/golang-blueprint/apifirst
+
+go 1.25.0
+
+require (
+	github.com/getkin/kin-openapi v0.142.0
+	github.com/go-chi/chi/v5 v5.0.8
+	github.com/maxatome/go-testdeep v1.13.0
+	github.com/oapi-codegen/runtime v1.7.0
+	github.com/oklog/ulid/v2 v2.1.0
+	github.com/rs/xid v1.5.0
+	github.com/rs/zerolog v1.29.1
+	github.com/urfave/cli/v2 v2.25.4
+	github.com/valyala/fastrand v1.1.0
+	golang.org/x/sync v0.22.0
+)
+
+require (
+	github.com/apapsch/go-jsonmerge/v2 v2.0.0 // indirect
+	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/go-openapi/jsonpointer v0.23.1 // indirect
+	github.com/go-openapi/swag/jsonname v0.26.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/oasdiff/yaml v0.1.1 // indirect
+	github.com/oasdiff/yaml3 v0.0.14 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/russross/blackfriday/v2 v2.1.0 // indirect
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2 // indirect
+	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+)
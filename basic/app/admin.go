@@ -0,0 +1,126 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/health"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Branch    string `json:"branch"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// AdminOptions configures the admin listener, which serves probes, metrics and profiles
+// apart from the API, so they are neither exposed to clients nor affected by TLS of the API.
+type AdminOptions struct {
+	Addr string
+
+	// Metrics is the registry of the app metrics served by /metrics, the HTTP metrics
+	// are registered in it too. A registry of the runtime metrics is created if nil.
+	Metrics *prometheus.Registry
+
+	// Health holds checks of the dependencies reported by /readyz, no checks are run if nil.
+	Health *health.Registry
+
+	// Flags holds the feature flags listed by /flags, no flags are listed if nil.
+	Flags *flags.Evaluator
+
+	BuildInfo BuildInfo
+}
+
+func (s *Server) newAdminRouter() chi.Router {
+	router := chi.NewRouter()
+	router.Use(middleware.Recoverer)
+
+	router.Get("/livez", s.livez)
+	router.Get("/readyz", s.readyz)
+	router.Get("/buildinfo", s.buildInfo)
+	router.Get("/flags", s.flags)
+	router.Handle("/metrics", promhttp.InstrumentMetricHandler(
+		s.adminOpts.Metrics, promhttp.HandlerFor(s.adminOpts.Metrics, promhttp.HandlerOpts{}),
+	))
+	router.Mount("/debug", middleware.Profiler())
+
+	return router
+}
+
+func (*Server) livez(w http.ResponseWriter, _ *http.Request) {
+	w.Write([]byte("ok")) //nolint: errcheck
+}
+
+// readyz reports whether the app accepts requests: it is started, not shutting down
+// and no critical health check fails. The health report is written as JSON,
+// a degraded app is still ready.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	report := health.Report{Status: health.StatusUp, Checks: []health.Result{}}
+	if s.adminOpts.Health != nil {
+		report = s.adminOpts.Health.Report(r.Context())
+	}
+
+	for _, res := range report.Checks {
+		if res.Status == health.StatusDown {
+			s.logger.Warnf("Health check %s failed: %s", res.Name, res.Error)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if report.Status == health.StatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report) //nolint: errcheck
+}
+
+func (s *Server) buildInfo(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.adminOpts.BuildInfo) //nolint: errcheck
+}
+
+// flagState represents a feature flag listed by /flags.
+type flagState struct {
+	flags.Flag
+
+	// EnabledFor reports whether the flag is enabled for the tenant and the principal
+	// given by the query, it is omitted if neither is given.
+	EnabledFor *bool `json:"enabled_for,omitempty"`
+}
+
+// flags lists the loaded feature flags as JSON. The flags are evaluated for the tenant
+// and the principal subject given by the tenant and principal query parameters, if any.
+func (s *Server) flags(w http.ResponseWriter, r *http.Request) {
+	tenantID, subject := r.URL.Query().Get("tenant"), r.URL.Query().Get("principal")
+
+	states := []flagState{}
+
+	if s.adminOpts.Flags != nil {
+		for _, f := range s.adminOpts.Flags.Flags() {
+			state := flagState{Flag: f}
+
+			if tenantID != "" || subject != "" {
+				enabled := f.EnabledFor(tenantID, subject)
+				state.EnabledFor = &enabled
+			}
+
+			states = append(states, state)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"flags": states}) //nolint: errcheck
+}
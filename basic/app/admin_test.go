@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/health"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type staticFlags []flags.Flag
+
+func (f staticFlags) Flags(context.Context) ([]flags.Flag, error) { return f, nil }
+
+func TestServer_admin(t *testing.T) {
+	type tcase struct {
+		ready  bool
+		checks []health.Check
+		flags  []flags.Flag
+		path   string
+
+		wantStatus int
+		wantBody   any
+		wantJSON   td.TestDeep
+	}
+
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := map[string]tcase{
+		"Live": {
+			path:       "/livez",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		"Ready": {
+			ready:      true,
+			checks:     []health.Check{{Name: "postgres", Severity: health.Critical, Check: ok}},
+			path:       "/readyz",
+			wantStatus: http.StatusOK,
+			wantJSON:   td.JSON(`{"status":"up","checks":[{"name":"postgres","severity":"critical","status":"up","duration_ns":Gte(0),"checked_at":NotEmpty()}]}`),
+		},
+		"Failing critical check": {
+			ready: true,
+			checks: []health.Check{
+				{Name: "postgres", Severity: health.Critical, Check: failing},
+				{Name: "migrations", Severity: health.Critical, Check: ok},
+			},
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantJSON: td.JSON(`{
+				"status": "down",
+				"checks": [
+					SuperMapOf({"name":"postgres","status":"down","error":"connection refused"}),
+					SuperMapOf({"name":"migrations","status":"up"}),
+				],
+			}`),
+		},
+		"Failing non-critical check": {
+			ready: true,
+			checks: []health.Check{
+				{Name: "postgres", Severity: health.Critical, Check: ok},
+				{Name: "jwks", Severity: health.NonCritical, Check: failing},
+			},
+			path:       "/readyz",
+			wantStatus: http.StatusOK,
+			wantJSON:   td.SuperJSONOf(`{"status":"degraded"}`),
+		},
+		"Shutting down": {
+			checks:     []health.Check{{Name: "postgres", Severity: health.Critical, Check: ok}},
+			path:       "/readyz",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "shutting down\n",
+		},
+		"Build info": {
+			path:       "/buildinfo",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"branch":"main","commit":"abc","build_time":"","go_version":""}` + "\n",
+		},
+		"Flags": {
+			flags:      []flags.Flag{{Name: "cat.search", Tenants: []string{"acme"}}},
+			path:       "/flags",
+			wantStatus: http.StatusOK,
+			wantJSON:   td.JSON(`{"flags":[{"name":"cat.search","enabled":false,"tenants":["acme"]}]}`),
+		},
+		"Flags evaluated": {
+			flags: []flags.Flag{
+				{Name: "cat.search", Tenants: []string{"acme"}},
+				{Name: "cat.export", Principals: []string{"user-2"}},
+			},
+			path:       "/flags?tenant=acme&principal=user-1",
+			wantStatus: http.StatusOK,
+			wantJSON: td.JSON(`{"flags":[
+				SuperMapOf({"name":"cat.export","enabled_for":false}),
+				SuperMapOf({"name":"cat.search","enabled_for":true}),
+			]}`),
+		},
+		"No flags": {
+			path:       "/flags",
+			wantStatus: http.StatusOK,
+			wantJSON:   td.JSON(`{"flags":[]}`),
+		},
+		"Metrics": {
+			path:       "/metrics",
+			wantStatus: http.StatusOK,
+			wantBody:   td.Contains("go_goroutines"),
+		},
+		"Profiles": {
+			path:       "/debug/pprof/cmdline",
+			wantStatus: http.StatusOK,
+			wantBody:   td.NotEmpty(),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTestServer(t, HTTPOptions{})
+			registry := health.NewRegistry(health.Options{})
+			for _, c := range tc.checks {
+				td.CmpNoError(t, registry.Register(c))
+			}
+
+			s.adminOpts = AdminOptions{Health: registry, BuildInfo: BuildInfo{Branch: "main", Commit: "abc"}}
+
+			if tc.flags != nil {
+				s.adminOpts.Flags = flags.NewEvaluator(staticFlags(tc.flags))
+				_, err := s.adminOpts.Flags.Reload(context.Background())
+				td.CmpNoError(t, err)
+			}
+			s.ready.Store(tc.ready)
+
+			server := httptest.NewServer(s.admin.Handler)
+			t.Cleanup(server.Close)
+
+			res, err := http.Get(server.URL + tc.path) //nolint: noctx
+			td.CmpNoError(t, err)
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			td.CmpNoError(t, err)
+
+			td.Cmp(t, res.StatusCode, tc.wantStatus)
+
+			if tc.wantJSON != nil {
+				var got any
+				td.CmpNoError(t, json.Unmarshal(body, &got))
+				td.Cmp(t, got, tc.wantJSON)
+
+				return
+			}
+
+			td.Cmp(t, string(body), tc.wantBody)
+		})
+	}
+}
+
+func TestServer_apiDoesNotExposeAdmin(t *testing.T) {
+	server := httptest.NewServer(newTestServer(t, HTTPOptions{}).router)
+	t.Cleanup(server.Close)
+
+	for _, path := range []string{"/metrics", "/health", "/debug/pprof/"} {
+		res, err := http.Get(server.URL + path) //nolint: noctx
+		td.CmpNoError(t, err)
+		res.Body.Close()
+
+		td.Cmp(t, res.StatusCode, http.StatusNotFound, path)
+	}
+}
@@ -0,0 +1,245 @@
+// Package config defines the configuration of the app. It is loaded from
+// the defaults, a YAML or TOML file, environment variables and flags,
+// each source overriding the previous one, see Config.Flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/ratelimit"
+	"github.com/go-playground/validator/v10"
+	"github.com/urfave/cli/v2"
+)
+
+// Config holds the configuration of the serve command.
+type Config struct {
+	Env      string `validate:"oneof=dev stage prod"`
+	LogLevel string `validate:"oneof=debug info warn error"`
+
+	HTTP    HTTP
+	GRPC    GRPC
+	Admin   Admin
+	DB      DB
+	Metrics Metrics
+	Tracing Tracing
+	Auth    Auth
+
+	DefaultTenant  string
+	RateLimit      RateLimit
+	IdempotencyTTL time.Duration `validate:"gt=0"`
+
+	// IdempotencyMaxBodySize and IdempotencyMaxResponseSize limit bodies of requests
+	// with the Idempotency-Key header and their recorded responses in bytes.
+	IdempotencyMaxBodySize     int64 `validate:"gt=0"`
+	IdempotencyMaxResponseSize int64 `validate:"gt=0"`
+
+	CORS     CORS
+	TLS      TLS
+	Features Features
+
+	// File is the path to the config file, which is watched for changes every ReloadInterval.
+	File           string
+	ReloadInterval time.Duration `validate:"gte=0"`
+
+	flags []cli.Flag
+	lists lists
+
+	// secretFiles are names of the secrets read from their files.
+	secretFiles map[string]bool
+}
+
+// HTTP holds the configuration of the HTTP listener.
+type HTTP struct {
+	Addr string `validate:"required"`
+
+	ReadTimeout       time.Duration `validate:"gt=0"`
+	ReadHeaderTimeout time.Duration `validate:"gt=0"`
+	WriteTimeout      time.Duration `validate:"gt=0"`
+	IdleTimeout       time.Duration `validate:"gt=0"`
+	ShutdownTimeout   time.Duration `validate:"gt=0"`
+
+	HSTSMaxAge     time.Duration
+	TrustedProxies []string
+}
+
+// GRPC holds the configuration of the gRPC listener.
+type GRPC struct {
+	Addr string `validate:"required"`
+}
+
+// Admin holds the configuration of the listener of probes, metrics and profiles.
+type Admin struct {
+	Addr               string `validate:"required"`
+	ShutdownDrainDelay time.Duration
+	HealthCacheTTL     time.Duration
+}
+
+// DB holds the configuration of the database connection pool.
+// Zero sizes and durations keep the defaults of pgxpool.
+type DB struct {
+	ConnStr     string `validate:"required"`
+	ConnStrFile string
+
+	// Password overrides the password of the connection string, PasswordFile is watched for rotations.
+	Password     string
+	PasswordFile string
+
+	Migrate          bool
+	RowLevelSecurity bool
+
+	MaxConns        int `validate:"gte=0"`
+	MinConns        int `validate:"gte=0"`
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// Metrics holds the configuration of the HTTP metrics.
+type Metrics struct {
+	Namespace        string
+	DurationBuckets  []float64
+	NativeHistograms bool
+}
+
+// Tracing holds the configuration of the OpenTelemetry tracing.
+type Tracing struct {
+	Exporter    string  `validate:"oneof=none otlp stdout"`
+	SampleRatio float64 `validate:"gte=0,lte=1"`
+}
+
+// Auth holds the configuration of the authentication of callers.
+type Auth struct {
+	Mode     string `validate:"oneof=jwt header"`
+	JWKS     string `validate:"required_if=Mode jwt"`
+	Issuer   string `validate:"required_if=Mode jwt"`
+	Audience string `validate:"required_if=Mode jwt"`
+	Leeway   time.Duration
+}
+
+// RateLimit holds the configuration of the rate limiter.
+type RateLimit struct {
+	Default string
+	Routes  []string
+
+	// IP limits every IP address before the authentication, see IPRules.
+	IP string
+}
+
+// CORS holds the configuration of the cross-origin requests.
+type CORS struct {
+	Origins     []string
+	Methods     []string
+	Credentials bool
+	MaxAge      time.Duration
+}
+
+// TLS holds the configuration of TLS of the API listeners.
+type TLS struct {
+	Cert           string        `validate:"required_with=Key"`
+	Key            string        `validate:"required_with=Cert"`
+	ClientCA       string        `validate:"excluded_without=Cert"`
+	ClientAuth     string        `validate:"oneof=require verify-if-given"`
+	ReloadInterval time.Duration `validate:"gt=0"`
+}
+
+// Features toggles optional features of the API.
+type Features struct {
+	GraphQL bool
+	Docs    bool
+
+	// FlagsProvider is the source of the feature flags, see package flags.
+	FlagsProvider       string        `validate:"oneof=file postgres"`
+	FlagsFile           string        `validate:"required_if=FlagsProvider file"`
+	FlagsReloadInterval time.Duration `validate:"gt=0"`
+}
+
+// New returns a pointer to a new instance of Config, its fields are set by Load.
+func New() *Config {
+	return &Config{}
+}
+
+// Validate checks the configuration by the field tags and the rules
+// which involve several fields or parsing of the values.
+func (c *Config) Validate() error {
+	if err := validator.New().Struct(c); err != nil {
+		return err
+	}
+
+	var errs []error
+
+	if slices.Contains(c.CORS.Origins, "*") && c.CORS.Credentials {
+		errs = append(errs, errors.New("cors: credentials can not be allowed for any origin, list the origins"))
+	}
+
+	for i := 1; i < len(c.Metrics.DurationBuckets); i++ {
+		if c.Metrics.DurationBuckets[i] <= c.Metrics.DurationBuckets[i-1] {
+			errs = append(errs, errors.New("metrics: duration buckets must be in increasing order"))
+			break
+		}
+	}
+
+	if c.DB.MaxConns > 0 && c.DB.MinConns > c.DB.MaxConns {
+		errs = append(errs, fmt.Errorf("db: min conns %d exceed max conns %d", c.DB.MinConns, c.DB.MaxConns))
+	}
+
+	if _, err := c.RateLimit.Rules(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := c.RateLimit.IPRules(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := c.HTTP.TrustedProxyPrefixes(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Rules parses the default and route rate limits.
+func (r RateLimit) Rules() (ratelimit.Rules, error) {
+	var (
+		rules ratelimit.Rules
+		err   error
+	)
+
+	if rules.Default, err = ratelimit.ParseLimit(r.Default); err != nil {
+		return ratelimit.Rules{}, fmt.Errorf("parse rate limit: %w", err)
+	}
+
+	for _, route := range r.Routes {
+		parsed, err := ratelimit.ParseRoute(route)
+		if err != nil {
+			return ratelimit.Rules{}, fmt.Errorf("parse route rate limit: %w", err)
+		}
+
+		rules.Routes = append(rules.Routes, parsed)
+	}
+
+	return rules, nil
+}
+
+// IPRules parses the rate limit of IP addresses, it applies to every route.
+func (r RateLimit) IPRules() (ratelimit.Rules, error) {
+	limit, err := ratelimit.ParseLimit(r.IP)
+	if err != nil {
+		return ratelimit.Rules{}, fmt.Errorf("parse ip rate limit: %w", err)
+	}
+
+	return ratelimit.Rules{Default: limit}, nil
+}
+
+// TrustedProxyPrefixes parses the addresses of the trusted proxies.
+func (h HTTP) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes, err := middlewares.ParseTrustedProxies(h.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("parse trusted proxies: %w", err)
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,425 @@
+package config
+
+import (
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/health"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tracing"
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+)
+
+const (
+	// FileFlag is the name of the flag which defines the path to the config file.
+	FileFlag = "config"
+
+	// FileSuffix is appended to the names of the secret flags to name their variants,
+	// which read the secrets from files, e.g. db-conn-str-file and DB_CONN_STR_FILE.
+	FileSuffix = "-file"
+
+	// DefaultReloadInterval is the default interval of checking the config file for changes.
+	DefaultReloadInterval = 10 * time.Second
+)
+
+// lists hold values of the slice flags until they are copied to Config by Load.
+type lists struct {
+	trustedProxies  cli.StringSlice
+	durationBuckets cli.Float64Slice
+	rateLimitRoutes cli.StringSlice
+	corsOrigins     cli.StringSlice
+	corsMethods     cli.StringSlice
+}
+
+// Flags returns the flags bound to the fields of the config. Every flag,
+// except the config file flag itself, may be set by the config file under
+// the key of the flag name, e.g. "http-read-timeout: 10s" in YAML.
+// The order of precedence is: flags, environment variables, the config file, the defaults.
+func (c *Config) Flags() []cli.Flag {
+	if c.flags != nil {
+		return c.flags
+	}
+
+	c.flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        FileFlag,
+			Usage:       "defines path to YAML (.yaml, .yml) or TOML (.toml) config file, its keys are the flag names",
+			Destination: &c.File,
+			EnvVars:     []string{"CONFIG_FILE"},
+		},
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "config-reload-interval",
+			Usage:       "defines how often the config file is checked for changes, 0 disables the check, SIGHUP reloads it anyway",
+			Destination: &c.ReloadInterval,
+			Value:       DefaultReloadInterval,
+			EnvVars:     []string{"CONFIG_RELOAD_INTERVAL"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "env",
+			Usage:       "defines app runtime environment",
+			EnvVars:     []string{"ENV"},
+			Value:       "dev",
+			Destination: &c.Env,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "log-level",
+			Usage:       "defines the minimal level of log lines: debug, info, warn, error",
+			Destination: &c.LogLevel,
+			Value:       "info",
+			EnvVars:     []string{"LOG_LEVEL"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name: "trace-exporter",
+			Usage: "defines where spans are sent: none, otlp, stdout, " +
+				"the otlp endpoint is defined by OTEL_EXPORTER_OTLP_ENDPOINT",
+			Destination: &c.Tracing.Exporter,
+			Value:       string(tracing.ExporterNone),
+			EnvVars:     []string{"TRACE_EXPORTER"},
+		}),
+		altsrc.NewFloat64Flag(&cli.Float64Flag{
+			Name:        "trace-sample-ratio",
+			Usage:       "defines the ratio of sampled traces started by the app, sampled parents are respected",
+			Destination: &c.Tracing.SampleRatio,
+			Value:       1,
+			EnvVars:     []string{"TRACE_SAMPLE_RATIO"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "http-addr",
+			Usage:       "defines HTTP listener address",
+			EnvVars:     []string{"HTTP_ADDR"},
+			Destination: &c.HTTP.Addr,
+			Value:       ":8080",
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "http-read-timeout",
+			Usage:       "defines how long the HTTP listener reads a request including the body",
+			EnvVars:     []string{"HTTP_READ_TIMEOUT"},
+			Destination: &c.HTTP.ReadTimeout,
+			Value:       app.DefaultReadTimeout,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "http-read-header-timeout",
+			Usage:       "defines how long the HTTP listener reads request headers",
+			EnvVars:     []string{"HTTP_READ_HEADER_TIMEOUT"},
+			Destination: &c.HTTP.ReadHeaderTimeout,
+			Value:       app.DefaultReadHeaderTimeout,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "http-write-timeout",
+			Usage:       "defines how long the HTTP listener writes a response",
+			EnvVars:     []string{"HTTP_WRITE_TIMEOUT"},
+			Destination: &c.HTTP.WriteTimeout,
+			Value:       app.DefaultWriteTimeout,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "http-idle-timeout",
+			Usage:       "defines how long the HTTP listener keeps idle connections",
+			EnvVars:     []string{"HTTP_IDLE_TIMEOUT"},
+			Destination: &c.HTTP.IdleTimeout,
+			Value:       app.DefaultIdleTimeout,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "shutdown-timeout",
+			Usage:       "defines how long the listeners finish pending requests on shutdown",
+			EnvVars:     []string{"SHUTDOWN_TIMEOUT"},
+			Destination: &c.HTTP.ShutdownTimeout,
+			Value:       app.DefaultShutdownTimeout,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "grpc-addr",
+			Usage:       "defines gRPC listener address",
+			EnvVars:     []string{"GRPC_ADDR"},
+			Destination: &c.GRPC.Addr,
+			Value:       ":9090",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "admin-addr",
+			Usage:       "defines the address of the listener of probes, metrics and profiles",
+			EnvVars:     []string{"ADMIN_ADDR"},
+			Destination: &c.Admin.Addr,
+			Value:       ":8081",
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "shutdown-drain-delay",
+			Usage:       "defines how long the app reports not ready before the listeners shut down",
+			EnvVars:     []string{"SHUTDOWN_DRAIN_DELAY"},
+			Destination: &c.Admin.ShutdownDrainDelay,
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "health-cache-ttl",
+			Usage:       "defines how long health check results are reused by the readiness probes",
+			EnvVars:     []string{"HEALTH_CACHE_TTL"},
+			Destination: &c.Admin.HealthCacheTTL,
+			Value:       health.DefaultCacheTTL,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "metrics-namespace",
+			Usage:       "defines prefix of the HTTP metric names, so they don't clash with other services",
+			Destination: &c.Metrics.Namespace,
+			Value:       "basicrest",
+			EnvVars:     []string{"METRICS_NAMESPACE"},
+		}),
+		altsrc.NewFloat64SliceFlag(&cli.Float64SliceFlag{
+			Name:        "metrics-duration-bucket",
+			Usage:       "defines upper bound in seconds of an HTTP request duration bucket, 5ms to 10s by default",
+			Destination: &c.lists.durationBuckets,
+			EnvVars:     []string{"METRICS_DURATION_BUCKETS"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "metrics-native-histograms",
+			Usage:       "defines whether HTTP histograms are exposed as native ones along with the buckets",
+			Destination: &c.Metrics.NativeHistograms,
+			EnvVars:     []string{"METRICS_NATIVE_HISTOGRAMS"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "db-conn-str",
+			Usage:       "defines database connection string",
+			Destination: &c.DB.ConnStr,
+			EnvVars:     []string{"DB_CONN_STR"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "db-conn-str" + FileSuffix,
+			Usage:       "defines path to the file which holds database connection string",
+			Destination: &c.DB.ConnStrFile,
+			EnvVars:     []string{"DB_CONN_STR_FILE"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "db-password",
+			Usage:       "defines database password, overrides the one of the connection string and PGPASSFILE",
+			Destination: &c.DB.Password,
+			EnvVars:     []string{"DB_PASSWORD"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "db-password" + FileSuffix,
+			Usage:       "defines path to the file which holds database password, rotations are picked up by new connections",
+			Destination: &c.DB.PasswordFile,
+			EnvVars:     []string{"DB_PASSWORD_FILE"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "db-migrate",
+			Usage:       "defines whether the app should run database migrations before start",
+			Destination: &c.DB.Migrate,
+			Value:       false,
+			EnvVars:     []string{"DB_MIGRATE"},
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "db-max-conns",
+			Usage:       "defines maximum size of the database connection pool, 0 keeps the default of pgxpool",
+			Destination: &c.DB.MaxConns,
+			EnvVars:     []string{"DB_MAX_CONNS"},
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "db-min-conns",
+			Usage:       "defines minimum size of the database connection pool",
+			Destination: &c.DB.MinConns,
+			EnvVars:     []string{"DB_MIN_CONNS"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "db-max-conn-lifetime",
+			Usage:       "defines how long a database connection is used before it is closed, 0 keeps the default of pgxpool",
+			Destination: &c.DB.MaxConnLifetime,
+			EnvVars:     []string{"DB_MAX_CONN_LIFETIME"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "db-max-conn-idle-time",
+			Usage:       "defines how long an idle database connection is kept, 0 keeps the default of pgxpool",
+			Destination: &c.DB.MaxConnIdleTime,
+			EnvVars:     []string{"DB_MAX_CONN_IDLE_TIME"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "auth-mode",
+			Usage:       "defines how callers are authenticated: jwt bearer tokens or header set by a trusted gateway",
+			Destination: &c.Auth.Mode,
+			Value:       "jwt",
+			EnvVars:     []string{"AUTH_MODE"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "auth-jwks",
+			Usage:       "defines JSON Web Key Set URL or file path to verify bearer tokens",
+			Destination: &c.Auth.JWKS,
+			EnvVars:     []string{"AUTH_JWKS"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "auth-issuer",
+			Usage:       "defines expected issuer of bearer tokens",
+			Destination: &c.Auth.Issuer,
+			EnvVars:     []string{"AUTH_ISSUER"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "auth-audience",
+			Usage:       "defines expected audience of bearer tokens",
+			Destination: &c.Auth.Audience,
+			EnvVars:     []string{"AUTH_AUDIENCE"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "auth-leeway",
+			Usage:       "defines allowed clock skew for bearer tokens expiration checks",
+			Destination: &c.Auth.Leeway,
+			Value:       30 * time.Second,
+			EnvVars:     []string{"AUTH_LEEWAY"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "default-tenant",
+			Usage:       "defines tenant of callers which neither are bound to a tenant nor send the X-Tenant-ID header, empty makes the tenant mandatory",
+			Destination: &c.DefaultTenant,
+			EnvVars:     []string{"DEFAULT_TENANT"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "db-row-level-security",
+			Usage:       "defines whether the tenant is set for every database transaction to enforce row level security",
+			Destination: &c.DB.RowLevelSecurity,
+			Value:       false,
+			EnvVars:     []string{"DB_ROW_LEVEL_SECURITY"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "rate-limit",
+			Usage:       "defines default rate limit of every client per route as <requests>/<period>, 0 requests disable it",
+			Destination: &c.RateLimit.Default,
+			Value:       "600/1m",
+			EnvVars:     []string{"RATE_LIMIT"},
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:        "rate-limit-route",
+			Usage:       "defines rate limit of routes as [<method> ]<path prefix>=<requests>/<period>, e.g. 'POST /v1/cat=10/1m'",
+			Destination: &c.lists.rateLimitRoutes,
+			EnvVars:     []string{"RATE_LIMIT_ROUTES"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "rate-limit-ip",
+			Usage:       "defines rate limit of every IP address before the authentication as <requests>/<period>, 0 requests disable it",
+			Destination: &c.RateLimit.IP,
+			Value:       "1200/1m",
+			EnvVars:     []string{"RATE_LIMIT_IP"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "idempotency-ttl",
+			Usage:       "defines how long responses of requests with Idempotency-Key header are replayed",
+			Destination: &c.IdempotencyTTL,
+			Value:       middlewares.DefaultIdempotencyTTL,
+			EnvVars:     []string{"IDEMPOTENCY_TTL"},
+		}),
+		altsrc.NewInt64Flag(&cli.Int64Flag{
+			Name:        "idempotency-max-body-size",
+			Usage:       "defines maximum size in bytes of the body of requests with Idempotency-Key header",
+			Destination: &c.IdempotencyMaxBodySize,
+			Value:       middlewares.DefaultIdempotencyMaxBodySize,
+			EnvVars:     []string{"IDEMPOTENCY_MAX_BODY_SIZE"},
+		}),
+		altsrc.NewInt64Flag(&cli.Int64Flag{
+			Name:        "idempotency-max-response-size",
+			Usage:       "defines maximum size in bytes of the recorded response body, larger responses are not replayed",
+			Destination: &c.IdempotencyMaxResponseSize,
+			Value:       middlewares.DefaultIdempotencyMaxResponseSize,
+			EnvVars:     []string{"IDEMPOTENCY_MAX_RESPONSE_SIZE"},
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:        "cors-origin",
+			Usage:       "defines origin allowed to call the API from browsers, e.g. https://*.example.com, none by default",
+			Destination: &c.lists.corsOrigins,
+			EnvVars:     []string{"CORS_ORIGINS"},
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:        "cors-method",
+			Usage:       "defines method allowed in cross-origin requests, GET, HEAD, POST, PUT, PATCH and DELETE by default",
+			Destination: &c.lists.corsMethods,
+			EnvVars:     []string{"CORS_METHODS"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "cors-credentials",
+			Usage:       "defines whether cross-origin requests may carry cookies and HTTP authentication",
+			Destination: &c.CORS.Credentials,
+			EnvVars:     []string{"CORS_CREDENTIALS"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "cors-max-age",
+			Usage:       "defines how long browsers may cache preflight responses",
+			Destination: &c.CORS.MaxAge,
+			Value:       10 * time.Minute,
+			EnvVars:     []string{"CORS_MAX_AGE"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "hsts-max-age",
+			Usage:       "defines how long browsers must use HTTPS only, 0 disables HSTS",
+			Destination: &c.HTTP.HSTSMaxAge,
+			Value:       365 * 24 * time.Hour,
+			EnvVars:     []string{"HSTS_MAX_AGE"},
+		}),
+		altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+			Name:        "trusted-proxy",
+			Usage:       "defines IP address or CIDR range of a proxy whose X-Forwarded-For header is trusted",
+			Destination: &c.lists.trustedProxies,
+			EnvVars:     []string{"TRUSTED_PROXIES"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-cert",
+			Usage:       "defines path to PEM encoded server certificate chain, enables TLS of both listeners",
+			Destination: &c.TLS.Cert,
+			EnvVars:     []string{"TLS_CERT"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-key",
+			Usage:       "defines path to PEM encoded private key of the server certificate",
+			Destination: &c.TLS.Key,
+			EnvVars:     []string{"TLS_KEY"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-client-ca",
+			Usage:       "defines path to PEM encoded CA bundle, enables verification of client certificates",
+			Destination: &c.TLS.ClientCA,
+			EnvVars:     []string{"TLS_CLIENT_CA"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "tls-client-auth",
+			Usage:       "defines whether clients must present certificates: require, verify-if-given",
+			Destination: &c.TLS.ClientAuth,
+			Value:       string(certs.ClientAuthRequire),
+			EnvVars:     []string{"TLS_CLIENT_AUTH"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "tls-reload-interval",
+			Usage:       "defines how often the certificate files are checked for changes",
+			Destination: &c.TLS.ReloadInterval,
+			Value:       certs.DefaultReloadInterval,
+			EnvVars:     []string{"TLS_RELOAD_INTERVAL"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "feature-graphql",
+			Usage:       "defines whether the GraphQL endpoint and GraphiQL are served",
+			Destination: &c.Features.GraphQL,
+			Value:       true,
+			EnvVars:     []string{"FEATURE_GRAPHQL"},
+		}),
+		altsrc.NewBoolFlag(&cli.BoolFlag{
+			Name:        "feature-docs",
+			Usage:       "defines whether the OpenAPI document and Swagger UI are served",
+			Destination: &c.Features.Docs,
+			Value:       true,
+			EnvVars:     []string{"FEATURE_DOCS"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "feature-flags-provider",
+			Usage:       "defines the source of the feature flags: postgres (the feature_flags table) or file",
+			Destination: &c.Features.FlagsProvider,
+			Value:       "postgres",
+			EnvVars:     []string{"FEATURE_FLAGS_PROVIDER"},
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "feature-flags-file",
+			Usage:       "defines path to YAML file of the feature flags, required by the file provider",
+			Destination: &c.Features.FlagsFile,
+			EnvVars:     []string{"FEATURE_FLAGS_FILE"},
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "feature-flags-reload-interval",
+			Usage:       "defines how often the feature flags are reloaded from the provider",
+			Destination: &c.Features.FlagsReloadInterval,
+			Value:       flags.DefaultReloadInterval,
+			EnvVars:     []string{"FEATURE_FLAGS_RELOAD_INTERVAL"},
+		}),
+	}
+
+	return c.flags
+}
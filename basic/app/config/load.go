@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/secrets"
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+	"gopkg.in/yaml.v3"
+)
+
+// Mask replaces values of the secrets when the configuration is printed.
+const Mask = "******"
+
+// secretFlags are names of the flags whose values are masked.
+// Each of them has a variant which reads the secret from a file, see FileSuffix.
+var secretFlags = map[string]bool{
+	"db-conn-str": true,
+	"db-password": true,
+}
+
+// Value represents a configuration value named by its flag.
+type Value struct {
+	Name  string
+	Value any
+}
+
+// Load applies the values of the config file to the flags which are set neither
+// by the command line nor by environment variables. It must be called by
+// the Before hook of the command whose flags are returned by Flags.
+func (c *Config) Load(ctx *cli.Context) error {
+	if path := ctx.String(FileFlag); path != "" {
+		src, err := c.fileSource(path)
+		if err != nil {
+			return err
+		}
+
+		if err := altsrc.ApplyInputSourceValues(ctx, src, c.Flags()); err != nil {
+			return fmt.Errorf("apply config file: %w", err)
+		}
+	}
+
+	if err := c.readSecretFiles(ctx); err != nil {
+		return err
+	}
+
+	c.setLists()
+
+	return nil
+}
+
+// setLists sets the list fields from the values of their flags.
+func (c *Config) setLists() {
+	c.HTTP.TrustedProxies = c.lists.trustedProxies.Value()
+	c.Metrics.DurationBuckets = c.lists.durationBuckets.Value()
+	c.RateLimit.Routes = c.lists.rateLimitRoutes.Value()
+	c.CORS.Origins = c.lists.corsOrigins.Value()
+	c.CORS.Methods = c.lists.corsMethods.Value()
+}
+
+// Values returns the configuration values in the order of the flags,
+// values of the secrets are masked.
+func (c *Config) Values() []Value {
+	return c.values(true)
+}
+
+func (c *Config) values(mask bool) []Value {
+	var values []Value
+
+	for _, f := range c.Flags() {
+		name := f.Names()[0]
+		if name == FileFlag {
+			continue
+		}
+
+		var v any
+
+		switch f := f.(type) {
+		case *altsrc.StringFlag:
+			v = *f.Destination
+
+			switch {
+			case c.secretFiles[name]:
+				v = "" // The secret is read from the file, which is printed instead.
+			case mask && secretFlags[name] && *f.Destination != "":
+				v = Mask
+			}
+		case *altsrc.BoolFlag:
+			v = *f.Destination
+		case *altsrc.IntFlag:
+			v = *f.Destination
+		case *altsrc.Int64Flag:
+			v = *f.Destination
+		case *altsrc.Float64Flag:
+			v = *f.Destination
+		case *altsrc.DurationFlag:
+			v = f.Destination.String()
+		case *altsrc.StringSliceFlag:
+			v = f.Destination.Value()
+		case *altsrc.Float64SliceFlag:
+			v = f.Destination.Value()
+		}
+
+		values = append(values, Value{Name: name, Value: v})
+	}
+
+	return values
+}
+
+// Print writes the configuration values to w as YAML,
+// which is accepted back as the config file.
+func (c *Config) Print(w io.Writer) error {
+	doc := yaml.Node{Kind: yaml.MappingNode}
+
+	for _, v := range c.Values() {
+		var value yaml.Node
+		if err := value.Encode(v.Value); err != nil {
+			return fmt.Errorf("encode %s: %w", v.Name, err)
+		}
+
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: v.Name}, &value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+
+	return enc.Close()
+}
+
+// readSecretFiles sets the secrets from the files defined by their variant flags.
+// A secret must not be set along with its file.
+func (c *Config) readSecretFiles(ctx *cli.Context) error {
+	for _, name := range slices.Sorted(maps.Keys(secretFlags)) {
+		path := ctx.String(name + FileSuffix)
+		if path == "" {
+			continue
+		}
+
+		if ctx.String(name) != "" {
+			return fmt.Errorf("%s and %s are mutually exclusive", name, name+FileSuffix)
+		}
+
+		secret, err := secrets.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Set(name, secret); err != nil {
+			return fmt.Errorf("set %s: %w", name, err)
+		}
+
+		if c.secretFiles == nil {
+			c.secretFiles = make(map[string]bool)
+		}
+
+		c.secretFiles[name] = true
+	}
+
+	return nil
+}
+
+// fileSource parses the config file. Its keys must be the names of the flags.
+func (c *Config) fileSource(path string) (altsrc.InputSourceContext, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s has unknown extension '%s', expected .yaml, .yml or .toml", path, ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	flags := make(map[string]cli.Flag)
+	for _, f := range c.Flags() {
+		flags[f.Names()[0]] = f
+	}
+
+	var errs []error
+
+	src := make(map[interface{}]interface{}, len(values))
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		f, ok := flags[key]
+		if !ok || key == FileFlag {
+			errs = append(errs, fmt.Errorf("unknown key '%s'", key))
+			continue
+		}
+
+		src[key] = normalize(f, values[key])
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return altsrc.NewMapInputSource(path, src), nil
+}
+
+// normalize converts numbers of the config file to the types expected by the flag,
+// e.g. YAML and TOML decode 1 as an integer, while the flag may expect a float.
+func normalize(f cli.Flag, v any) any {
+	switch f.(type) {
+	case *altsrc.IntFlag:
+		if n, ok := v.(int64); ok {
+			return int(n)
+		}
+	case *altsrc.Float64Flag:
+		return toFloat(v)
+	case *altsrc.Float64SliceFlag:
+		if list, ok := v.([]any); ok {
+			floats := make([]any, len(list))
+			for i, item := range list {
+				floats[i] = toFloat(item)
+			}
+
+			return floats
+		}
+	}
+
+	return v
+}
+
+func toFloat(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+
+	return v
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/urfave/cli/v2"
+	"github.com/urfave/cli/v2/altsrc"
+)
+
+// reloadable are names of the flags whose values are applied without a restart.
+var reloadable = map[string]bool{
+	"log-level":        true,
+	"cors-origin":      true,
+	"rate-limit":       true,
+	"rate-limit-route": true,
+	"rate-limit-ip":    true,
+	"feature-graphql":  true,
+	"feature-docs":     true,
+}
+
+// Change represents a configuration value changed by a reload.
+type Change struct {
+	Name       string
+	Old, New   any
+	Reloadable bool
+}
+
+// Parse loads and validates a new configuration from the command line arguments,
+// environment variables and the config file, like the serve command does.
+func Parse(args []string) (*Config, error) {
+	cfg := New()
+
+	app := cli.App{
+		Name:      "reload",
+		Flags:     cfg.Flags(),
+		Before:    cfg.Load,
+		Action:    func(*cli.Context) error { return nil },
+		HideHelp:  true,
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+	}
+
+	if err := app.Run(append([]string{app.Name}, args...)); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Diff returns the values which differ between the configurations,
+// values of the secrets are masked.
+func Diff(old, new *Config) []Change {
+	oldValues, newValues := old.values(false), new.values(false)
+	oldMasked, newMasked := old.Values(), new.Values()
+
+	var changes []Change
+
+	for i, v := range newValues {
+		if fmt.Sprint(v.Value) == fmt.Sprint(oldValues[i].Value) {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Name:       v.Name,
+			Old:        oldMasked[i].Value,
+			New:        newMasked[i].Value,
+			Reloadable: reloadable[v.Name],
+		})
+	}
+
+	return changes
+}
+
+// Watcher reloads the configuration on SIGHUP and on changes of the config file.
+type Watcher struct {
+	args   []string
+	logger log.Logger
+	apply  func(cfg *Config) error
+
+	mu      sync.Mutex
+	current *Config
+}
+
+// NewWatcher returns a pointer to a new instance of Watcher, which parses args on reload
+// and passes the new configuration to apply if any reloadable value has changed.
+func NewWatcher(current *Config, args []string, logger log.Logger, apply func(cfg *Config) error) *Watcher {
+	w := Watcher{
+		args:    args,
+		logger:  logger,
+		apply:   apply,
+		current: current,
+	}
+
+	return &w
+}
+
+// Current returns the configuration in use.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Reload parses the configuration and applies its reloadable values. Changes of
+// other values are logged, they require a restart, so Current keeps their values
+// in use. The configuration in use is kept if the new one is invalid or can not be applied.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := Parse(w.args)
+	if err != nil {
+		return fmt.Errorf("reload config: %w", err)
+	}
+
+	var apply bool
+
+	for _, c := range Diff(w.current, next) {
+		logger := w.logger.With(log.String("name", c.Name), log.Any("old", c.Old), log.Any("new", c.New))
+
+		if !c.Reloadable {
+			logger.Warnf("Config value %s has changed, restart is required to apply it", c.Name)
+			continue
+		}
+
+		logger.Infof("Config value %s has changed", c.Name)
+
+		apply = true
+	}
+
+	// Values requiring a restart are not in use, so they keep warning on the next reloads.
+	keepValues(next, w.current, func(name string) bool { return !reloadable[name] })
+
+	if apply {
+		if err := w.apply(next); err != nil {
+			return fmt.Errorf("apply config: %w", err)
+		}
+	}
+
+	w.current = next
+
+	return nil
+}
+
+// keepValues sets the values of the flags of dst matching keep to the values of src.
+func keepValues(dst, src *Config, keep func(name string) bool) {
+	srcFlags := src.Flags()
+
+	for i, f := range dst.Flags() {
+		name := f.Names()[0]
+		if !keep(name) {
+			continue
+		}
+
+		switch f := f.(type) {
+		case *cli.StringFlag:
+			*f.Destination = *srcFlags[i].(*cli.StringFlag).Destination
+		case *altsrc.StringFlag:
+			*f.Destination = *srcFlags[i].(*altsrc.StringFlag).Destination
+		case *altsrc.BoolFlag:
+			*f.Destination = *srcFlags[i].(*altsrc.BoolFlag).Destination
+		case *altsrc.IntFlag:
+			*f.Destination = *srcFlags[i].(*altsrc.IntFlag).Destination
+		case *altsrc.Int64Flag:
+			*f.Destination = *srcFlags[i].(*altsrc.Int64Flag).Destination
+		case *altsrc.Float64Flag:
+			*f.Destination = *srcFlags[i].(*altsrc.Float64Flag).Destination
+		case *altsrc.DurationFlag:
+			*f.Destination = *srcFlags[i].(*altsrc.DurationFlag).Destination
+		case *altsrc.StringSliceFlag:
+			*f.Destination = *cli.NewStringSlice(srcFlags[i].(*altsrc.StringSliceFlag).Destination.Value()...)
+		case *altsrc.Float64SliceFlag:
+			*f.Destination = *cli.NewFloat64Slice(srcFlags[i].(*altsrc.Float64SliceFlag).Destination.Value()...)
+		}
+
+		if src.secretFiles[name] {
+			if dst.secretFiles == nil {
+				dst.secretFiles = make(map[string]bool)
+			}
+
+			dst.secretFiles[name] = true
+		} else {
+			delete(dst.secretFiles, name)
+		}
+	}
+
+	dst.setLists()
+}
+
+// Watch reloads the configuration on SIGHUP received from signals and, if interval
+// is positive, whenever the content of the config file changes, until ctx is done.
+// Failures are logged, the configuration in use is kept.
+func (w *Watcher) Watch(ctx context.Context, signals <-chan os.Signal, interval time.Duration) {
+	var (
+		tick    <-chan time.Time
+		content []byte
+	)
+
+	path := w.Current().File
+	if path != "" && interval > 0 {
+		if data, err := os.ReadFile(path); err == nil {
+			content = data
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				continue
+			}
+
+			w.logger.Infof("Reloading config on %s", sig)
+		case <-tick:
+			data, err := os.ReadFile(path)
+			if err != nil || bytes.Equal(data, content) {
+				continue
+			}
+
+			content = data
+
+			w.logger.Infof("Reloading config on change of '%s'", path)
+		}
+
+		if err := w.Reload(); err != nil {
+			w.logger.Errorf("Failed to reload config, the current one is kept: %s", err.Error())
+		}
+	}
+}
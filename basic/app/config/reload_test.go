@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestDiff(t *testing.T) {
+	old, err := Parse([]string{"--db-conn-str", "postgres://old", "--auth-mode", "header"})
+	td.CmpNoError(t, err)
+
+	next, err := Parse([]string{
+		"--db-conn-str", "postgres://new", "--auth-mode", "header",
+		"--log-level", "debug", "--cors-origin", "https://a.example.com",
+	})
+	td.CmpNoError(t, err)
+
+	td.Cmp(t, Diff(old, next), []Change{
+		{Name: "log-level", Old: "info", New: "debug", Reloadable: true},
+		{Name: "db-conn-str", Old: Mask, New: Mask, Reloadable: false},
+		{Name: "cors-origin", Old: td.Empty(), New: []string{"https://a.example.com"}, Reloadable: true},
+	})
+
+	td.CmpEmpty(t, Diff(old, old))
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	type tcase struct {
+		file     string
+		applyErr error
+
+		wantApplied  bool
+		wantLogLevel string
+		wantErr      string
+	}
+
+	tests := map[string]tcase{
+		"reloadable change": {
+			file:         "log-level: debug\n",
+			wantApplied:  true,
+			wantLogLevel: "debug",
+		},
+		"restart is required": {
+			file:         "http-addr: :9090\n",
+			wantLogLevel: "info",
+		},
+		"invalid": {
+			file:         "log-level: verbose\n",
+			wantLogLevel: "info",
+			wantErr:      "reload config: .*LogLevel.*oneof",
+		},
+		"apply failure": {
+			file:         "log-level: debug\n",
+			applyErr:     errors.New("boom"),
+			wantApplied:  true,
+			wantLogLevel: "info",
+			wantErr:      "apply config: boom",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, "config.yaml", "")
+			args := []string{"--config", path, "--db-conn-str", "postgres://localhost", "--auth-mode", "header"}
+
+			current, err := Parse(args)
+			td.CmpNoError(t, err)
+
+			var applied bool
+
+			w := NewWatcher(current, args, log.DisabledLogger(), func(*Config) error {
+				applied = true
+				return tc.applyErr
+			})
+
+			td.CmpNoError(t, os.WriteFile(path, []byte(tc.file), 0o600))
+
+			err = w.Reload()
+			if tc.wantErr != "" {
+				td.Cmp(t, err.Error(), td.Re(tc.wantErr))
+			} else {
+				td.CmpNoError(t, err)
+			}
+
+			td.Cmp(t, applied, tc.wantApplied)
+			td.Cmp(t, w.Current().LogLevel, tc.wantLogLevel)
+		})
+	}
+}
+
+func TestWatcher_Reload_restartRequired(t *testing.T) {
+	path := writeFile(t, "config.yaml", "")
+	args := []string{"--config", path, "--db-conn-str", "postgres://localhost", "--auth-mode", "header"}
+
+	current, err := Parse(args)
+	td.CmpNoError(t, err)
+
+	w := NewWatcher(current, args, log.DisabledLogger(), func(*Config) error { return nil })
+
+	td.CmpNoError(t, os.WriteFile(path, []byte("http-addr: :9090\nlog-level: debug\n"), 0o600))
+
+	for range 2 {
+		td.CmpNoError(t, w.Reload())
+
+		// The listener keeps its address until a restart, so the change is reported again.
+		td.Cmp(t, w.Current().HTTP.Addr, current.HTTP.Addr)
+		td.Cmp(t, w.Current().LogLevel, "debug")
+
+		next, err := Parse(args)
+		td.CmpNoError(t, err)
+		td.Cmp(t, Diff(w.Current(), next), []Change{
+			{Name: "http-addr", Old: current.HTTP.Addr, New: ":9090", Reloadable: false},
+		})
+	}
+}
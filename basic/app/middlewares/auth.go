@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+)
+
+// AuthMiddleware represents middleware which authenticates requests by the given
+// extractor, e.g. by JWT bearer tokens with auth.Verifier. The principal is put into
+// the request context, see auth.PrincipalFromContext. Requests without valid credentials
+// are rejected with 401 problem details response and a WWW-Authenticate challenge
+// per scheme of the extractor, see auth.Extractor.Schemes.
+// More about bearer token usage: https://www.rfc-editor.org/rfc/rfc6750
+func AuthMiddleware(logger log.Logger, extractor auth.Extractor) func(next http.Handler) http.Handler {
+	schemes := extractor.Schemes()
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			principal, err := extractor.Extract(r.Context(), r.Header)
+			if err != nil {
+				description := "the access token is invalid"
+
+				switch {
+				case errors.Is(err, auth.ErrNoCredentials):
+					// No error code is sent to a client which did not try to authenticate.
+					writeUnauthorized(w, r, schemes, "", "missing credentials")
+					return
+				case errors.Is(err, auth.ErrTokenExpired):
+					description = "the access token expired"
+				case !errors.Is(err, auth.ErrInvalidToken):
+					logger.Errorf("Failed to authenticate request: %s", err.Error())
+				}
+
+				writeUnauthorized(w, r, schemes, `error="invalid_token", error_description="`+description+`"`, description)
+				return
+			}
+
+			ctx := log.ContextWith(r.Context(), log.String("principal", principal.Subject))
+
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithPrincipal(ctx, principal)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// writeUnauthorized writes 401 problem details response with a challenge per scheme.
+// The error params are added to the Bearer challenge only, as defined by RFC 6750.
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, schemes []string, params, detail string) {
+	for _, scheme := range schemes {
+		challenge := scheme
+		if params != "" && strings.EqualFold(scheme, "Bearer") {
+			challenge += " " + params
+		}
+
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+
+	writeProblem(w, r, http.StatusUnauthorized, detail)
+}
+
+// writeProblem writes problem details response about the request.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problem.WriteRequest(w, r, status, detail) //nolint: errcheck
+}
@@ -0,0 +1,198 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth/authtest"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	key := authtest.NewECKey(t, "test-key")
+
+	keys, err := auth.NewJWKS(context.Background(), authtest.WriteJWKS(t, key), auth.JWKSOptions{})
+	td.CmpNoError(t, err)
+
+	verifier := auth.NewVerifier(keys, auth.VerifierOptions{
+		Issuer:   "https://issuer.test",
+		Audience: "cat-api",
+	})
+
+	handler := AuthMiddleware(log.DisabledLogger(), verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := auth.PrincipalFromContext(r.Context())
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte(p.Subject)) //nolint: errcheck
+	}))
+
+	claims := func(exp time.Duration) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    "https://issuer.test",
+			Audience:  jwt.ClaimStrings{"cat-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+		}
+	}
+
+	type tcase struct {
+		authorization string
+
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+		wantDetail    string
+	}
+
+	tests := map[string]tcase{
+		"Valid token": {
+			authorization: "Bearer " + key.Sign(t, claims(time.Hour)),
+			wantStatus:    http.StatusOK,
+			wantBody:      "user-1",
+		},
+		"Case insensitive scheme": {
+			authorization: "bearer " + key.Sign(t, claims(time.Hour)),
+			wantStatus:    http.StatusOK,
+			wantBody:      "user-1",
+		},
+		"Missing header": {
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer`,
+			wantDetail:    "missing credentials",
+		},
+		"Other scheme": {
+			authorization: "Basic dXNlcjpwYXNz",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer`,
+			wantDetail:    "missing credentials",
+		},
+		"Invalid token": {
+			authorization: "Bearer invalid",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="the access token is invalid"`,
+			wantDetail:    "the access token is invalid",
+		},
+		"Token of unknown key": {
+			authorization: "Bearer " + authtest.NewECKey(t, "test-key").Sign(t, claims(time.Hour)),
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="the access token is invalid"`,
+			wantDetail:    "the access token is invalid",
+		},
+		"Expired token": {
+			authorization: "Bearer " + key.Sign(t, claims(-time.Hour)),
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="the access token expired"`,
+			wantDetail:    "the access token expired",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cat/test-id", http.NoBody)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			td.Cmp(t, rec.Code, tc.wantStatus)
+
+			if tc.wantStatus == http.StatusOK {
+				td.Cmp(t, rec.Body.String(), tc.wantBody)
+				return
+			}
+
+			td.Cmp(t, rec.Header().Get("WWW-Authenticate"), tc.wantChallenge)
+			td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+
+			var p problem.Details
+			td.CmpNoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+			td.Cmp(t, p, problem.Details{
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Detail:   tc.wantDetail,
+				Instance: "/v1/cat/test-id",
+			})
+		})
+	}
+}
+
+func TestAuthMiddleware_challenges(t *testing.T) {
+	apiKeys := testExtractor{scheme: "ApiKey", err: auth.ErrInvalidToken}
+	bearer := testExtractor{scheme: "Bearer", err: auth.ErrInvalidToken}
+
+	type tcase struct {
+		extractor     auth.Extractor
+		authorization string
+
+		wantChallenges []string
+	}
+
+	tests := map[string]tcase{
+		"API keys and bearer tokens": {
+			extractor:      auth.Extractors{apiKeys, bearer},
+			wantChallenges: []string{"ApiKey", "Bearer"},
+		},
+		"API keys only": {
+			extractor:      auth.Extractors{apiKeys},
+			wantChallenges: []string{"ApiKey"},
+		},
+		"Invalid credentials": {
+			extractor:     auth.Extractors{apiKeys, bearer},
+			authorization: "ApiKey invalid",
+			wantChallenges: []string{
+				"ApiKey",
+				`Bearer error="invalid_token", error_description="the access token is invalid"`,
+			},
+		},
+		"Header extractor": {
+			extractor: auth.NewHeaderExtractor(),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cat/test-id", http.NoBody)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			AuthMiddleware(log.DisabledLogger(), tc.extractor)(http.NotFoundHandler()).ServeHTTP(rec, req)
+
+			td.Cmp(t, rec.Code, http.StatusUnauthorized)
+			td.Cmp(t, rec.Header().Values("WWW-Authenticate"), tc.wantChallenges)
+		})
+	}
+}
+
+// testExtractor fails with err if the Authorization header has the scheme.
+type testExtractor struct {
+	scheme string
+	err    error
+}
+
+func (e testExtractor) Extract(_ context.Context, header http.Header) (*auth.Principal, error) {
+	if scheme, _, _ := strings.Cut(header.Get("Authorization"), " "); scheme != e.scheme {
+		return nil, auth.ErrNoCredentials
+	}
+
+	return nil, e.err
+}
+
+func (e testExtractor) Schemes() []string {
+	return []string{e.scheme}
+}
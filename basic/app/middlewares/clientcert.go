@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientCertMiddleware represents middleware which puts the identity of the client
+// authenticated by a TLS certificate into the request context, see certs.PeerFromContext.
+// Requests without a verified client certificate are passed as is.
+func ClientCertMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := certs.PeerFromConnectionState(r.TLS); ok {
+				r = r.WithContext(certs.ContextWithPeer(r.Context(), p))
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// GRPCClientCertInterceptor puts the identity of the client authenticated by
+// a TLS certificate into the call context, see certs.PeerFromContext.
+func GRPCClientCertInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if pr, ok := peer.FromContext(ctx); ok {
+			if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok {
+				if p, ok := certs.PeerFromConnectionState(&info.State); ok {
+					ctx = certs.ContextWithPeer(ctx, p)
+				}
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
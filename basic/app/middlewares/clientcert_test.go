@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs/certstest"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestClientCertMiddleware(t *testing.T) {
+	client := certstest.NewCA(t, "test-ca").IssueClient(t, "client-1", "spiffe://example.org/client-1")
+
+	type tcase struct {
+		state *tls.ConnectionState
+
+		wantPeer *certs.Peer
+	}
+
+	tests := map[string]tcase{
+		"Verified certificate": {
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client.Cert}}},
+			wantPeer: &certs.Peer{
+				Subject:      "client-1",
+				URIs:         []string{"spiffe://example.org/client-1"},
+				SerialNumber: client.Cert.SerialNumber.Text(16),
+			},
+		},
+		"Unverified certificate": {
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client.Cert}},
+		},
+		"Plain HTTP": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got *certs.Peer
+
+			handler := ClientCertMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got, _ = certs.PeerFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.TLS = tc.state
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			td.Cmp(t, got, tc.wantPeer)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CORSOptions configures CORSMiddleware.
+type CORSOptions struct {
+	// AllowedOrigins lists origins allowed to call the API, e.g. https://app.example.com.
+	// One wildcard matches subdomains, e.g. https://*.example.com, * allows any origin.
+	// Empty list disables CORS.
+	AllowedOrigins []string
+
+	// Origins, if set, holds the allowed origins instead of AllowedOrigins,
+	// so they can be replaced while the server runs, e.g. on a configuration reload.
+	Origins *CORSOrigins
+
+	// AllowedMethods lists methods allowed in cross-origin requests,
+	// GET, HEAD, POST, PUT, PATCH and DELETE if empty.
+	AllowedMethods []string
+
+	// AllowedHeaders lists request headers allowed in cross-origin requests,
+	// the headers of the API, e.g. Authorization and Idempotency-Key, if empty.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists response headers which are readable by browser apps,
+	// the headers of the API, e.g. Retry-After and RateLimit-*, if empty.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies and HTTP authentication.
+	// Browsers refuse it along with the * origin.
+	AllowCredentials bool
+
+	// MaxAge is the time browsers may cache preflight responses for.
+	MaxAge time.Duration
+}
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+
+	defaultCORSHeaders = []string{
+		"Accept", "Authorization", "Content-Type", "Idempotency-Key", "X-API-Key", "X-Tenant-ID",
+	}
+
+	defaultCORSExposedHeaders = []string{
+		"Idempotent-Replayed", "Location", "RateLimit-Limit", "RateLimit-Policy",
+		"RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "WWW-Authenticate", "X-Request-ID",
+	}
+)
+
+// CORSOrigins holds the origins allowed by CORSMiddleware.
+type CORSOrigins struct {
+	origins atomic.Pointer[[]string]
+}
+
+// NewCORSOrigins returns a pointer to a new instance of CORSOrigins holding the origins.
+func NewCORSOrigins(origins []string) *CORSOrigins {
+	o := CORSOrigins{}
+	o.Set(origins)
+
+	return &o
+}
+
+// Set replaces the allowed origins, it is safe to call concurrently with serving requests.
+func (o *CORSOrigins) Set(origins []string) {
+	origins = slices.Clone(origins)
+	o.origins.Store(&origins)
+}
+
+// Get returns the allowed origins.
+func (o *CORSOrigins) Get() []string {
+	return *o.origins.Load()
+}
+
+// CORSMiddleware represents middleware which implements cross-origin resource sharing,
+// so browser apps from the allowed origins can call the API. Preflight requests are
+// answered without calling the next handler, so it must run before AuthMiddleware.
+// More about CORS: https://fetch.spec.whatwg.org/#http-cors-protocol
+func CORSMiddleware(opts CORSOptions) func(next http.Handler) http.Handler {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = defaultCORSMethods
+	}
+
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = defaultCORSHeaders
+	}
+
+	if len(opts.ExposedHeaders) == 0 {
+		opts.ExposedHeaders = defaultCORSExposedHeaders
+	}
+
+	if opts.Origins == nil {
+		opts.Origins = NewCORSOrigins(opts.AllowedOrigins)
+	}
+
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	allowedHeader := make(map[string]bool, len(opts.AllowedHeaders))
+	for _, h := range opts.AllowedHeaders {
+		allowedHeader[http.CanonicalHeaderKey(h)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			allowedOrigins := opts.Origins.Get()
+			if len(allowedOrigins) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			anyOrigin := slices.Contains(allowedOrigins, "*")
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !(anyOrigin || matchOrigin(allowedOrigins, origin)) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent) // Without CORS headers the browser rejects the request.
+					return
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				h.Set("Access-Control-Expose-Headers", exposed)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(opts.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			for _, requested := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+				if requested = strings.TrimSpace(requested); requested != "" && !allowedHeader[http.CanonicalHeaderKey(requested)] {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+
+			h.Set("Access-Control-Allow-Methods", methods)
+			h.Set("Access-Control-Allow-Headers", headers)
+
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// matchOrigin reports whether the origin matches one of the allowed origins.
+func matchOrigin(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+
+		prefix, suffix, wildcard := strings.Cut(a, "*")
+		if !wildcard {
+			if a == origin {
+				return true
+			}
+
+			continue
+		}
+
+		// The wildcard matches non-empty labels only, so https://*.example.com
+		// does not match https://example.com or https://evil.com/.example.com.
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			label := origin[len(prefix) : len(origin)-len(suffix)]
+			if !strings.ContainsAny(label, "/:") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
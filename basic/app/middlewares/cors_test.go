@@ -0,0 +1,160 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	type tcase struct {
+		opts   CORSOptions
+		method string
+		header http.Header
+
+		wantStatus  int
+		wantOrigin  string
+		wantCreds   string
+		wantMethods string
+		wantMaxAge  string
+		wantExposed string
+	}
+
+	const exposed = "Idempotent-Replayed, Location, RateLimit-Limit, RateLimit-Policy, " +
+		"RateLimit-Remaining, RateLimit-Reset, Retry-After, WWW-Authenticate, X-Request-ID"
+
+	preflight := func(origin, method, headers string) http.Header {
+		return http.Header{
+			"Origin":                         {origin},
+			"Access-Control-Request-Method":  {method},
+			"Access-Control-Request-Headers": {headers},
+		}
+	}
+
+	tests := map[string]tcase{
+		"Allowed origin": {
+			opts:        CORSOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method:      http.MethodGet,
+			header:      http.Header{"Origin": {"https://app.example.com"}},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://app.example.com",
+			wantExposed: exposed,
+		},
+		"Wildcard subdomain": {
+			opts:        CORSOptions{AllowedOrigins: []string{"https://*.example.com"}},
+			method:      http.MethodGet,
+			header:      http.Header{"Origin": {"https://admin.example.com"}},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://admin.example.com",
+			wantExposed: exposed,
+		},
+		"Wildcard does not match the domain": {
+			opts:       CORSOptions{AllowedOrigins: []string{"https://*.example.com"}},
+			method:     http.MethodGet,
+			header:     http.Header{"Origin": {"https://example.com"}},
+			wantStatus: http.StatusOK,
+		},
+		"Other origin": {
+			opts:       CORSOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method:     http.MethodGet,
+			header:     http.Header{"Origin": {"https://evil.com"}},
+			wantStatus: http.StatusOK,
+		},
+		"Any origin": {
+			opts:        CORSOptions{AllowedOrigins: []string{"*"}},
+			method:      http.MethodGet,
+			header:      http.Header{"Origin": {"https://evil.com"}},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "*",
+			wantExposed: exposed,
+		},
+		"Credentials": {
+			opts:        CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			method:      http.MethodGet,
+			header:      http.Header{"Origin": {"https://app.example.com"}},
+			wantStatus:  http.StatusOK,
+			wantOrigin:  "https://app.example.com",
+			wantCreds:   "true",
+			wantExposed: exposed,
+		},
+		"Preflight": {
+			opts: CORSOptions{
+				AllowedOrigins: []string{"https://app.example.com"},
+				AllowedMethods: []string{http.MethodGet, http.MethodPost},
+				MaxAge:         10 * time.Minute,
+			},
+			method:      http.MethodOptions,
+			header:      preflight("https://app.example.com", http.MethodPost, "Authorization, idempotency-key"),
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://app.example.com",
+			wantMethods: "GET, POST",
+			wantMaxAge:  "600",
+		},
+		"Preflight of not allowed method": {
+			opts:       CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{http.MethodGet}},
+			method:     http.MethodOptions,
+			header:     preflight("https://app.example.com", http.MethodDelete, ""),
+			wantStatus: http.StatusNoContent,
+			wantOrigin: "https://app.example.com",
+		},
+		"Preflight of not allowed header": {
+			opts:       CORSOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method:     http.MethodOptions,
+			header:     preflight("https://app.example.com", http.MethodGet, "X-Custom"),
+			wantStatus: http.StatusNoContent,
+			wantOrigin: "https://app.example.com",
+		},
+		"Disabled": {
+			method:     http.MethodOptions,
+			header:     preflight("https://app.example.com", http.MethodGet, ""),
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/v1/cat", http.NoBody)
+			req.Header = tc.header
+
+			rec := httptest.NewRecorder()
+			CORSMiddleware(tc.opts)(next).ServeHTTP(rec, req)
+
+			td.Cmp(t, rec.Code, tc.wantStatus)
+			td.Cmp(t, rec.Header().Get("Access-Control-Allow-Origin"), tc.wantOrigin)
+			td.Cmp(t, rec.Header().Get("Access-Control-Allow-Credentials"), tc.wantCreds)
+			td.Cmp(t, rec.Header().Get("Access-Control-Allow-Methods"), tc.wantMethods)
+			td.Cmp(t, rec.Header().Get("Access-Control-Max-Age"), tc.wantMaxAge)
+			td.Cmp(t, rec.Header().Get("Access-Control-Expose-Headers"), tc.wantExposed)
+		})
+	}
+}
+
+func TestCORSMiddleware_origins(t *testing.T) {
+	origins := NewCORSOrigins(nil)
+	handler := CORSMiddleware(CORSOptions{Origins: origins})(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	allowedOrigin := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	td.Cmp(t, allowedOrigin(), "", "CORS is disabled")
+
+	origins.Set([]string{"https://*.example.com"})
+	td.Cmp(t, allowedOrigin(), "https://app.example.com")
+
+	origins.Set([]string{"https://other.example.com"})
+	td.Cmp(t, allowedOrigin(), "")
+}
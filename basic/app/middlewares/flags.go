@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+)
+
+// FlagsMiddleware represents middleware which puts the given evaluator of feature flags
+// into the request context, so handlers check the flags by flags.Enabled.
+// The flags are evaluated for the tenant and the principal of the request,
+// which are put into the context by TenantMiddleware and AuthMiddleware.
+func FlagsMiddleware(evaluator *flags.Evaluator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(flags.ContextWithEvaluator(r.Context(), evaluator)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
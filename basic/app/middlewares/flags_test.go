@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestFlagsMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	td.CmpNoError(t, os.WriteFile(path, []byte("cat.search:\n  tenants: [acme]\n"), 0o600))
+
+	evaluator := flags.NewEvaluator(flags.NewFileProvider(path))
+	_, err := evaluator.Reload(context.Background())
+	td.CmpNoError(t, err)
+
+	// The tenant is resolved after the evaluator is put into the context.
+	handler := FlagsMiddleware(evaluator)(TenantMiddleware(tenant.NewResolver(""))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(strconv.FormatBool(flags.Enabled(r.Context(), "cat.search")))) //nolint: errcheck
+		}),
+	))
+
+	for id, want := range map[string]string{"acme": "true", "globex": "false"} {
+		req := httptest.NewRequest(http.MethodGet, "/cats", nil)
+		req = req.WithContext(auth.ContextWithPrincipal(req.Context(),
+			&auth.Principal{Subject: "service", Scopes: []string{tenant.CrossTenantScope}}))
+		req.Header.Set(tenant.Header, id)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		td.Cmp(t, rec.Body.String(), want, id)
+	}
+}
@@ -0,0 +1,143 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCLoggingInterceptor represents logging interceptor for unary gRPC calls. It puts a call
+// scoped logger holding the request ID, trace ID and method into the call context, see log.FromContext,
+// and logs every call with its code and duration.
+func GRPCLoggingInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now().UTC()
+
+		remote := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+
+		reqID, _ := requestid.FromContext(ctx)
+
+		callLogger := logger.With(
+			log.String("request_id", reqID),
+			log.String("method", info.FullMethod),
+			log.String("remote", remote),
+		).With(traceFields(ctx)...)
+
+		// The inner interceptors add fields, e.g. the principal and the tenant, to the access log line.
+		callCtx, innerFields := log.ContextWithFields(log.WithContext(ctx, callLogger))
+
+		resp, err := handler(callCtx, req)
+		code := status.Code(err)
+
+		accessLogger := callLogger.With(innerFields.List()...).
+			With(log.String("code", code.String()), log.Duration("duration", time.Since(start)))
+
+		switch code {
+		case codes.OK:
+			accessLogger.Infof("%s %s", info.FullMethod, code.String())
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			accessLogger.Errorf("%s %s", info.FullMethod, code.String())
+		default:
+			accessLogger.Warnf("%s %s", info.FullMethod, code.String())
+		}
+
+		return resp, err
+	}
+}
+
+// GRPCRecoveryInterceptor recovers unary gRPC handlers from panics
+// and turns them into codes.Internal errors.
+func GRPCRecoveryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				logger.Errorf("Panic in %s: %s\n%s", info.FullMethod, fmt.Sprint(rvr), string(debug.Stack()))
+
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// GRPCAuthInterceptor authenticates unary gRPC calls by the given extractor,
+// which receives the incoming metadata as HTTP headers. The principal is put
+// into the call context, calls without valid credentials fail with codes.Unauthenticated.
+func GRPCAuthInterceptor(logger log.Logger, extractor auth.Extractor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		principal, err := extractor.Extract(ctx, incomingHeader(ctx))
+		if err != nil {
+			if !errors.Is(err, auth.ErrNoCredentials) && !errors.Is(err, auth.ErrInvalidToken) &&
+				!errors.Is(err, auth.ErrTokenExpired) {
+				logger.Errorf("Failed to authenticate %s call: %s", info.FullMethod, err.Error())
+			}
+
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid credentials")
+		}
+
+		ctx = log.ContextWith(ctx, log.String("principal", principal.Subject))
+
+		return handler(auth.ContextWithPrincipal(ctx, principal), req)
+	}
+}
+
+// GRPCTenantInterceptor resolves the tenant of unary gRPC calls by the given resolver
+// from the principal and the incoming metadata, the tenant is put into the call context.
+// It must run after GRPCAuthInterceptor. Calls selecting a foreign tenant fail with
+// codes.PermissionDenied, calls without a valid tenant with codes.InvalidArgument.
+func GRPCTenantInterceptor(resolver *tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id, err := resolver.Resolve(ctx, incomingHeader(ctx))
+		if err != nil {
+			if errors.Is(err, tenant.ErrMismatch) {
+				return nil, status.Error(codes.PermissionDenied, "the tenant is not accessible by the caller")
+			}
+
+			return nil, status.Error(codes.InvalidArgument, "missing or invalid tenant")
+		}
+
+		ctx = log.ContextWith(ctx, log.String("tenant", id))
+
+		return handler(tenant.ContextWithID(ctx, id), req)
+	}
+}
+
+// GRPCFlagsInterceptor puts the given evaluator of feature flags into the context
+// of unary gRPC calls, so handlers check the flags by flags.Enabled.
+func GRPCFlagsInterceptor(evaluator *flags.Evaluator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(flags.ContextWithEvaluator(ctx, evaluator), req)
+	}
+}
+
+// incomingHeader returns the incoming metadata of the call as HTTP headers.
+func incomingHeader(ctx context.Context) http.Header {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	return header
+}
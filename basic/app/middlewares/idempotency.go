@@ -0,0 +1,254 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idempotency"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// DefaultIdempotencyTTL is the default time a response is replayed for.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// DefaultIdempotencyLockTimeout is the default time after which an in-flight
+	// request is considered lost, it must exceed the write timeout of the server.
+	DefaultIdempotencyLockTimeout = time.Minute
+
+	// DefaultIdempotencyMaxBodySize is the default limit of the request body in bytes.
+	DefaultIdempotencyMaxBodySize = 1 << 20
+
+	// DefaultIdempotencyMaxResponseSize is the default limit of the recorded response body in bytes.
+	DefaultIdempotencyMaxResponseSize = 1 << 20
+
+	// maxIdempotencyKeyLen limits the length of keys sent by clients.
+	maxIdempotencyKeyLen = 255
+)
+
+// IdempotencyOptions configures IdempotencyMiddleware.
+type IdempotencyOptions struct {
+	// TTL is the time a response is replayed for, DefaultIdempotencyTTL if zero.
+	TTL time.Duration
+
+	// LockTimeout is the time after which an in-flight request is considered lost
+	// and the key can be used again, DefaultIdempotencyLockTimeout if zero.
+	LockTimeout time.Duration
+
+	// MaxBodySize limits the request body, which is read to hash it, larger requests are
+	// rejected with 413, DefaultIdempotencyMaxBodySize if zero.
+	MaxBodySize int64
+
+	// MaxResponseSize limits the recorded response body, larger responses are not recorded
+	// and the key can be used again, DefaultIdempotencyMaxResponseSize if zero.
+	MaxResponseSize int64
+}
+
+// IdempotencyMiddleware represents middleware which makes POST and PATCH requests with
+// the Idempotency-Key header safe to retry. The response of the first request is recorded
+// in the store and replayed for repeats with the same key and payload within the TTL.
+// A repeat is rejected with 409 while the first request is in flight, and a key reused
+// with a different payload with 422. Keys are scoped by the tenant and the principal,
+// so it must run after AuthMiddleware and TenantMiddleware. Server errors are not
+// recorded, so a request which failed that way can be retried.
+func IdempotencyMiddleware(
+	logger log.Logger, store idempotency.Store, opts IdempotencyOptions,
+) func(next http.Handler) http.Handler {
+	if opts.TTL == 0 {
+		opts.TTL = DefaultIdempotencyTTL
+	}
+
+	if opts.LockTimeout == 0 {
+		opts.LockTimeout = DefaultIdempotencyLockTimeout
+	}
+
+	if opts.MaxBodySize == 0 {
+		opts.MaxBodySize = DefaultIdempotencyMaxBodySize
+	}
+
+	if opts.MaxResponseSize == 0 {
+		opts.MaxResponseSize = DefaultIdempotencyMaxResponseSize
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.Header)
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLen {
+				writeProblem(w, r, http.StatusBadRequest, "the Idempotency-Key header is too long")
+				return
+			}
+
+			var maxBytesErr *http.MaxBytesError
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, opts.MaxBodySize))
+			switch {
+			case errors.As(err, &maxBytesErr):
+				writeProblem(w, r, http.StatusRequestEntityTooLarge, "the request body is too large")
+				return
+			case err != nil:
+				writeProblem(w, r, http.StatusBadRequest, "failed to read the request body")
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Postgres keeps microseconds, the reservation is matched by its creation time.
+			now := time.Now().UTC().Truncate(time.Microsecond)
+			record := idempotency.Record{
+				Scope:       idempotencyScope(r),
+				Key:         key,
+				RequestHash: idempotency.HashRequest(r.Method, r.URL.Path, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(opts.TTL),
+			}
+
+			existing, err := store.Reserve(r.Context(), &record, now.Add(-opts.LockTimeout))
+			switch {
+			case errors.Is(err, idempotency.ErrExists):
+				replay(w, r, &record, existing)
+				return
+			case err != nil:
+				logger.Errorf("Failed to reserve idempotency key: %s", err.Error())
+
+				writeProblem(w, r, http.StatusInternalServerError, "")
+				return
+			}
+
+			// The outcome is recorded even if the client is gone, it is going to retry.
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+
+			defer func() {
+				if completed {
+					return
+				}
+
+				if err := store.Release(ctx, &record); err != nil {
+					logger.Errorf("Failed to release idempotency key: %s", err.Error())
+				}
+			}()
+
+			// Headers set by the preceding middlewares are not part of the response to replay.
+			preset := make(map[string]bool, len(w.Header()))
+			for name := range w.Header() {
+				preset[name] = true
+			}
+
+			buf := limitedBuffer{limit: opts.MaxResponseSize}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			next.ServeHTTP(ww, r)
+
+			if ww.Status() >= http.StatusInternalServerError {
+				return
+			}
+
+			if buf.exceeded {
+				logger.Warnf("Idempotent response is not recorded, its body exceeds %d bytes", opts.MaxResponseSize)
+				return
+			}
+
+			record.Completed = true
+			record.StatusCode = ww.Status()
+			if record.StatusCode == 0 {
+				record.StatusCode = http.StatusOK // Nothing is written by the handler.
+			}
+
+			record.Body = buf.Bytes()
+			record.Header = make(http.Header)
+
+			for name, values := range w.Header() {
+				if !preset[name] {
+					record.Header[name] = values
+				}
+			}
+
+			switch err := store.Complete(ctx, &record); {
+			case errors.Is(err, idempotency.ErrReservationLost):
+				// Another request has taken the key over, neither Complete nor Release touch its record.
+				logger.Warnf("Idempotent response is not recorded, the reservation of the key is lost")
+				return
+			case err != nil:
+				logger.Errorf("Failed to record idempotent response: %s", err.Error())
+				return
+			}
+
+			completed = true
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// limitedBuffer collects written bytes up to the limit, it drops them all once the limit is exceeded.
+type limitedBuffer struct {
+	bytes.Buffer
+
+	limit    int64
+	exceeded bool
+}
+
+// Write never fails, so the response is written to the client even if it is not recorded.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded {
+		return len(p), nil
+	}
+
+	if int64(b.Len()+len(p)) > b.limit {
+		b.exceeded = true
+		b.Reset()
+
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// replay answers a repeated request with the recorded response.
+func replay(w http.ResponseWriter, r *http.Request, record, existing *idempotency.Record) {
+	switch {
+	case !bytes.Equal(existing.RequestHash, record.RequestHash):
+		writeProblem(w, r, http.StatusUnprocessableEntity, "the Idempotency-Key is already used with a different request")
+	case !existing.Completed:
+		w.Header().Set("Retry-After", "1")
+		writeProblem(w, r, http.StatusConflict, "a request with the Idempotency-Key is in progress")
+	default:
+		for name, values := range existing.Header {
+			w.Header()[name] = values
+		}
+
+		w.Header().Set(idempotency.ReplayedHeader, "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.Body) //nolint: errcheck
+	}
+}
+
+// idempotencyScope returns the scope of the request keys, which
+// keeps callers from replaying responses of each other.
+func idempotencyScope(r *http.Request) string {
+	var scope string
+
+	if id, ok := tenant.FromContext(r.Context()); ok {
+		scope = id
+	}
+
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok {
+		scope += "/" + p.Subject
+	}
+
+	return scope
+}
@@ -0,0 +1,303 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idempotency"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	store := newMemIdempotencyStore()
+
+	var calls int
+
+	status := http.StatusCreated
+	handler := IdempotencyMiddleware(log.DisabledLogger(), store, IdempotencyOptions{})(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+
+			w.Header().Set("Location", "/v1/cat/1")
+			w.WriteHeader(status)
+			w.Write([]byte("created")) //nolint: errcheck
+		}),
+	)
+
+	do := func(subject, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/cat", strings.NewReader(body))
+		req = req.WithContext(tenant.ContextWithID(
+			auth.ContextWithPrincipal(req.Context(), &auth.Principal{Subject: subject}), "acme",
+		))
+
+		if key != "" {
+			req.Header.Set(idempotency.Header, key)
+		}
+
+		rec := httptest.NewRecorder()
+		rec.Header().Set("RateLimit-Remaining", "10")
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		calls = 0
+
+		rec := do("user-1", "key-1", `{"name":"Tom"}`)
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Header().Get(idempotency.ReplayedHeader), "")
+
+		rec = do("user-1", "key-1", `{"name":"Tom"}`)
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Body.String(), "created")
+		td.Cmp(t, rec.Header().Get("Location"), "/v1/cat/1")
+		td.Cmp(t, rec.Header().Get(idempotency.ReplayedHeader), "true")
+		td.Cmp(t, calls, 1)
+
+		td.Cmp(t, store.records["acme/user-1|key-1"].Header, http.Header{"Location": {"/v1/cat/1"}})
+	})
+
+	t.Run("Different payload", func(t *testing.T) {
+		calls = 0
+
+		td.Cmp(t, do("user-1", "key-2", `{"name":"Tom"}`).Code, http.StatusCreated)
+		td.Cmp(t, do("user-1", "key-2", `{"name":"Jerry"}`).Code, http.StatusUnprocessableEntity)
+		td.Cmp(t, calls, 1)
+	})
+
+	t.Run("In flight", func(t *testing.T) {
+		calls = 0
+
+		_, err := store.Reserve(context.Background(), &idempotency.Record{
+			Scope:       "acme/user-1",
+			Key:         "key-3",
+			RequestHash: idempotency.HashRequest(http.MethodPost, "/v1/cat", []byte(`{}`)),
+			CreatedAt:   time.Now(),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}, time.Now().Add(-time.Minute))
+		td.CmpNoError(t, err)
+
+		rec := do("user-1", "key-3", `{}`)
+		td.Cmp(t, rec.Code, http.StatusConflict)
+		td.Cmp(t, rec.Header().Get("Retry-After"), "1")
+		td.Cmp(t, calls, 0)
+	})
+
+	t.Run("Other principal", func(t *testing.T) {
+		calls = 0
+
+		td.Cmp(t, do("user-1", "key-4", `{}`).Code, http.StatusCreated)
+		td.Cmp(t, do("user-2", "key-4", `{}`).Header().Get(idempotency.ReplayedHeader), "")
+		td.Cmp(t, calls, 2)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		calls = 0
+		status = http.StatusInternalServerError
+		defer func() { status = http.StatusCreated }()
+
+		td.Cmp(t, do("user-1", "key-5", `{}`).Code, http.StatusInternalServerError)
+		td.Cmp(t, do("user-1", "key-5", `{}`).Code, http.StatusInternalServerError)
+		td.Cmp(t, calls, 2)
+	})
+
+	t.Run("No key", func(t *testing.T) {
+		calls = 0
+
+		td.Cmp(t, do("user-1", "", `{}`).Code, http.StatusCreated)
+		td.Cmp(t, do("user-1", "", `{}`).Code, http.StatusCreated)
+		td.Cmp(t, calls, 2)
+	})
+
+	t.Run("Stale with different payload", func(t *testing.T) {
+		calls = 0
+
+		_, err := store.Reserve(context.Background(), &idempotency.Record{
+			Scope:       "acme/user-1",
+			Key:         "key-6",
+			RequestHash: idempotency.HashRequest(http.MethodPost, "/v1/cat", []byte(`{}`)),
+			CreatedAt:   time.Now().Add(-time.Hour),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}, time.Now().Add(-2*time.Hour))
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, do("user-1", "key-6", `{"name":"Jerry"}`).Code, http.StatusUnprocessableEntity)
+		td.Cmp(t, calls, 0)
+
+		// The lost request is retried with its payload.
+		td.Cmp(t, do("user-1", "key-6", `{}`).Code, http.StatusCreated)
+		td.Cmp(t, calls, 1)
+	})
+
+	t.Run("Long key", func(t *testing.T) {
+		td.Cmp(t, do("user-1", strings.Repeat("k", 256), `{}`).Code, http.StatusBadRequest)
+	})
+}
+
+func TestIdempotencyMiddleware_limits(t *testing.T) {
+	store := newMemIdempotencyStore()
+
+	var calls int
+
+	handler := IdempotencyMiddleware(log.DisabledLogger(), store, IdempotencyOptions{
+		MaxBodySize:     8,
+		MaxResponseSize: 4,
+	})(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created")) //nolint: errcheck
+		}),
+	)
+
+	do := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/cat", strings.NewReader(body))
+		req.Header.Set(idempotency.Header, "key-1")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Large request", func(t *testing.T) {
+		calls = 0
+
+		rec := do(`{"name":"Tom"}`)
+		td.Cmp(t, rec.Code, http.StatusRequestEntityTooLarge)
+		td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+		td.Cmp(t, calls, 0)
+	})
+
+	t.Run("Large response", func(t *testing.T) {
+		calls = 0
+
+		rec := do(`{}`)
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Body.String(), "created")
+
+		rec = do(`{}`)
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Header().Get(idempotency.ReplayedHeader), "")
+		td.Cmp(t, calls, 2)
+		td.Cmp(t, store.records, td.Empty())
+	})
+}
+
+func TestIdempotencyMiddleware_lostReservation(t *testing.T) {
+	store := newMemIdempotencyStore()
+
+	var takeover idempotency.Record
+
+	handler := IdempotencyMiddleware(log.DisabledLogger(), store, IdempotencyOptions{})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The request has gone stale, a retry takes the key over while it still runs.
+			takeover = store.records["|key-1"]
+			takeover.CreatedAt = takeover.CreatedAt.Add(time.Minute)
+
+			_, err := store.Reserve(r.Context(), &takeover, takeover.CreatedAt)
+			td.CmpNoError(t, err)
+
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/cat", strings.NewReader(`{}`))
+	req.Header.Set(idempotency.Header, "key-1")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	td.Cmp(t, rec.Code, http.StatusCreated)
+
+	// The reservation of the retry is neither completed nor released by the first request.
+	td.Cmp(t, store.records["|key-1"], takeover)
+}
+
+// memIdempotencyStore implements idempotency.Store in memory.
+type memIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{records: make(map[string]idempotency.Record)}
+}
+
+func (s *memIdempotencyStore) Reserve(
+	_ context.Context, r *idempotency.Record, staleBefore time.Time,
+) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[r.Scope+"|"+r.Key]
+	stale := !existing.Completed && existing.CreatedAt.Before(staleBefore) &&
+		bytes.Equal(existing.RequestHash, r.RequestHash)
+
+	if ok && existing.ExpiresAt.After(r.CreatedAt) && !stale {
+		return &existing, idempotency.ErrExists
+	}
+
+	s.records[r.Scope+"|"+r.Key] = *r
+
+	return r, nil
+}
+
+func (s *memIdempotencyStore) Complete(_ context.Context, r *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.reserved(r) {
+		return idempotency.ErrReservationLost
+	}
+
+	s.records[r.Scope+"|"+r.Key] = *r
+
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(_ context.Context, r *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reserved(r) {
+		delete(s.records, r.Scope+"|"+r.Key)
+	}
+
+	return nil
+}
+
+// reserved reports whether the in-flight record is the reservation of r.
+func (s *memIdempotencyStore) reserved(r *idempotency.Record) bool {
+	existing, ok := s.records[r.Scope+"|"+r.Key]
+
+	return ok && !existing.Completed && bytes.Equal(existing.RequestHash, r.RequestHash) &&
+		existing.CreatedAt.Equal(r.CreatedAt)
+}
+
+func (s *memIdempotencyStore) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+
+	for k, r := range s.records {
+		if !r.ExpiresAt.After(before) {
+			delete(s.records, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -1,32 +1,69 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tracing"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// LoggingMiddleware represents logging middlewares.
+// LoggingMiddleware represents logging middlewares. It puts a request scoped logger
+// holding the request ID, trace ID, method and path into the request context, see log.FromContext,
+// and logs every request with its route, status and duration, along with the fields added
+// by the inner middlewares by log.ContextWith, e.g. the principal and the tenant.
 func LoggingMiddleware(logger log.Logger) func(next http.Handler) http.Handler {
-	format := "%s %d %s Remote: %s %s"
-
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now().UTC()
 
+			reqID, _ := requestid.FromContext(r.Context())
+
+			reqLogger := logger.With(
+				log.String("request_id", reqID),
+				log.String("method", r.Method),
+				log.String("path", r.URL.Path),
+				log.String("remote", r.RemoteAddr),
+			).With(traceFields(r.Context())...)
+
+			// The inner middlewares add fields, e.g. the principal and the tenant, to the access log line.
+			ctx, innerFields := log.ContextWithFields(log.WithContext(r.Context(), reqLogger))
+
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-			next.ServeHTTP(ww, r.WithContext(r.Context()))
+			next.ServeHTTP(ww, r.WithContext(ctx))
 			status := ww.Status()
 
-			if status >= http.StatusBadRequest {
-				logger.Errorf(format, r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String())
-			} else {
-				logger.Infof(format, r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String())
+			fields := append(innerFields.List(), log.Int("status", status), log.Duration("duration", time.Since(start)))
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				fields = append(fields, log.String("route", rctx.RoutePattern()))
+			}
+
+			accessLogger := reqLogger.With(fields...)
+
+			switch {
+			case status >= http.StatusInternalServerError:
+				accessLogger.Errorf("%s %s %d", r.Method, r.RequestURI, status)
+			case status >= http.StatusBadRequest:
+				accessLogger.Warnf("%s %s %d", r.Method, r.RequestURI, status)
+			default:
+				accessLogger.Infof("%s %s %d", r.Method, r.RequestURI, status)
 			}
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// traceFields returns log fields holding IDs of the span of ctx, so log lines are matched to traces.
+func traceFields(ctx context.Context) []log.Field {
+	traceID, spanID, ok := tracing.IDs(ctx)
+	if !ok {
+		return nil
+	}
+
+	return []log.Field{log.String("trace_id", traceID), log.String("span_id", spanID)}
+}
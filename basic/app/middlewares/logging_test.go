@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	logger := &fieldsLogger{lines: new([]map[string]any)}
+
+	// The principal and the tenant are added after the logging middleware runs.
+	handler := LoggingMiddleware(logger)(
+		AuthMiddleware(log.DisabledLogger(), auth.NewHeaderExtractor())(
+			TenantMiddleware(tenant.NewResolver("acme"))(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+			),
+		),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/cat/1", http.NoBody)
+	req.Header.Set(auth.SubjectHeader, "user-1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	td.Cmp(t, *logger.lines, td.Bag(
+		td.SuperMapOf(map[string]any{
+			"method":    http.MethodGet,
+			"path":      "/v1/cat/1",
+			"principal": "user-1",
+			"tenant":    "acme",
+			"status":    http.StatusNoContent,
+		}, nil),
+	))
+}
+
+// fieldsLogger records the fields of every line.
+type fieldsLogger struct {
+	fields []log.Field
+	lines  *[]map[string]any
+}
+
+func (l *fieldsLogger) Debugf(string, ...any) { l.record() }
+func (l *fieldsLogger) Infof(string, ...any)  { l.record() }
+func (l *fieldsLogger) Warnf(string, ...any)  { l.record() }
+func (l *fieldsLogger) Errorf(string, ...any) { l.record() }
+
+func (l *fieldsLogger) With(fields ...log.Field) log.Logger {
+	return &fieldsLogger{fields: append(append([]log.Field(nil), l.fields...), fields...), lines: l.lines}
+}
+
+func (l *fieldsLogger) record() {
+	line := make(map[string]any, len(l.fields))
+	for _, f := range l.fields {
+		line[f.Key] = f.Value
+	}
+
+	*l.lines = append(*l.lines, line)
+}
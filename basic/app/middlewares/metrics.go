@@ -1,44 +1,160 @@
 package middlewares
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// UnmatchedRoute is the route label of requests which match no route,
+// so scans of random paths do not blow up the number of series.
+const UnmatchedRoute = "unmatched"
+
 var (
-	RespDurSec = promauto.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "requests_duration_seconds",
-		Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1},
-	}, []string{"method", "route", "code"})
-
-	RespTotal = promauto.NewCounterVec(prometheus.CounterOpts{
-		Name: "requests_total",
-	}, []string{"method", "route", "code"})
+	// DefaultDurationBuckets cover responses from 5ms to 10s.
+	DefaultDurationBuckets = prometheus.DefBuckets
+
+	// DefaultSizeBuckets cover bodies from 100B to 10MB.
+	DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
 )
 
+// MetricsOptions holds options of the MetricsMiddleware.
+type MetricsOptions struct {
+	// Namespace prefixes the names of the metrics, e.g. "basicrest_requests_total".
+	Namespace string
+
+	// DurationBuckets of the request duration histogram, DefaultDurationBuckets is used if empty.
+	DurationBuckets []float64
+
+	// SizeBuckets of the request and response size histograms, DefaultSizeBuckets is used if empty.
+	SizeBuckets []float64
+
+	// NativeHistograms exposes the histograms as native ones along with the classic buckets,
+	// Prometheus scrapes them if native histograms are enabled.
+	NativeHistograms bool
+}
+
 // MetricsMiddleware represents HTTP metrics collecting middlewares.
-func MetricsMiddleware() func(next http.Handler) http.Handler {
+// The metrics are registered in reg, their series are labelled by the method,
+// route, code and the operation set by the handler, see metrics.SetOperation.
+// Requests are counted in flight while they are served.
+func MetricsMiddleware(reg prometheus.Registerer, opts MetricsOptions) func(next http.Handler) http.Handler {
+	if len(opts.DurationBuckets) == 0 {
+		opts.DurationBuckets = DefaultDurationBuckets
+	}
+
+	if len(opts.SizeBuckets) == 0 {
+		opts.SizeBuckets = DefaultSizeBuckets
+	}
+
+	labels := []string{"method", "route", "code", "operation"}
+	factory := promauto.With(reg)
+
+	respDurSec := factory.NewHistogramVec(opts.histogram("requests_duration_seconds",
+		"Duration of HTTP requests.", opts.DurationBuckets), labels)
+
+	respTotal := factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: opts.Namespace,
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests.",
+	}, labels)
+
+	reqSize := factory.NewHistogramVec(opts.histogram("request_size_bytes",
+		"Size of HTTP request bodies.", opts.SizeBuckets), labels)
+
+	respSize := factory.NewHistogramVec(opts.histogram("response_size_bytes",
+		"Size of HTTP response bodies.", opts.SizeBuckets), labels)
+
+	inFlight := factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: opts.Namespace,
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests being served.",
+	})
+
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			inFlight.Inc()
+			defer inFlight.Dec()
+
 			start := time.Now()
-			ctx := chi.RouteContext(r.Context())
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+
+			r = r.WithContext(metrics.ContextWithOperation(r.Context()))
+
 			next.ServeHTTP(ww, r)
 
-			RespDurSec.WithLabelValues(r.Method, ctx.RoutePattern(), strconv.Itoa(ww.Status())).
-				Observe(time.Since(start).Seconds())
+			values := []string{
+				r.Method,
+				routeLabel(chi.RouteContext(r.Context())),
+				strconv.Itoa(ww.Status()),
+				metrics.Operation(r.Context()),
+			}
 
-			RespTotal.WithLabelValues(r.Method, ctx.RoutePattern(), strconv.Itoa(ww.Status())).
-				Inc()
+			respDurSec.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+			respTotal.WithLabelValues(values...).Inc()
+			reqSize.WithLabelValues(values...).Observe(float64(max(r.ContentLength, body.n)))
+			respSize.WithLabelValues(values...).Observe(float64(ww.BytesWritten()))
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+func (o MetricsOptions) histogram(name, help string, buckets []float64) prometheus.HistogramOpts {
+	h := prometheus.HistogramOpts{
+		Namespace: o.Namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}
+
+	if o.NativeHistograms {
+		h.NativeHistogramBucketFactor = 1.1
+		h.NativeHistogramMaxBucketNumber = 160
+		h.NativeHistogramMinResetDuration = time.Hour
+	}
+
+	return h
+}
+
+// routeLabel returns the pattern of the matched route. Requests which reach
+// only the wildcard of a mount, i.e. match no route of the mounted router,
+// are labelled by UnmatchedRoute.
+func routeLabel(rctx *chi.Context) string {
+	if rctx == nil {
+		return UnmatchedRoute
+	}
+
+	route := rctx.RoutePattern()
+	if route == "" || strings.HasSuffix(route, "*") {
+		return UnmatchedRoute
+	}
+
+	return route
+}
+
+// countingReader counts bytes read from the request body, whose length may be unknown.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	cat := chi.NewRouter()
+	cat.Get("/{id}", func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("cat")) }) //nolint: errcheck
+	cat.Post("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		metrics.SetOperation(r.Context(), "query getCat")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router := chi.NewRouter()
+	router.Route("/v1", func(v1 chi.Router) {
+		v1.Use(MetricsMiddleware(reg, MetricsOptions{Namespace: "test", DurationBuckets: []float64{1, 10}}))
+		v1.Mount("/cat", cat)
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/v1/cat/1", http.NoBody),
+		httptest.NewRequest(http.MethodGet, "/v1/cat/2", http.NoBody),
+		httptest.NewRequest(http.MethodGet, "/v1/cat/1/unknown", http.NoBody),
+		httptest.NewRequest(http.MethodGet, "/v1/unknown", http.NoBody),
+		httptest.NewRequest(http.MethodPost, "/v1/cat/graphql", strings.NewReader(`{"query":"{getCat(id:\"1\"){name}}"}`)),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP test_requests_total Number of HTTP requests.
+# TYPE test_requests_total counter
+test_requests_total{code="200",method="GET",operation="",route="/v1/cat/{id}"} 2
+test_requests_total{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql"} 1
+test_requests_total{code="404",method="GET",operation="",route="unmatched"} 2
+# HELP test_requests_in_flight Number of HTTP requests being served.
+# TYPE test_requests_in_flight gauge
+test_requests_in_flight 0
+`), "test_requests_total", "test_requests_in_flight")
+	td.CmpNoError(t, err)
+
+	td.Cmp(t, testutil.CollectAndCount(reg, "test_requests_duration_seconds"), 3)
+
+	// Size of the GraphQL request body, requests without body are observed as 0.
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP test_request_size_bytes Size of HTTP request bodies.
+# TYPE test_request_size_bytes histogram
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="100"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="1000"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="10000"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="100000"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="1e+06"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="1e+07"} 2
+test_request_size_bytes_bucket{code="200",method="GET",operation="",route="/v1/cat/{id}",le="+Inf"} 2
+test_request_size_bytes_sum{code="200",method="GET",operation="",route="/v1/cat/{id}"} 0
+test_request_size_bytes_count{code="200",method="GET",operation="",route="/v1/cat/{id}"} 2
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="100"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="1000"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="10000"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="100000"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="1e+06"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="1e+07"} 1
+test_request_size_bytes_bucket{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql",le="+Inf"} 1
+test_request_size_bytes_sum{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql"} 36
+test_request_size_bytes_count{code="200",method="POST",operation="query getCat",route="/v1/cat/graphql"} 1
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="100"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="1000"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="10000"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="100000"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="1e+06"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="1e+07"} 2
+test_request_size_bytes_bucket{code="404",method="GET",operation="",route="unmatched",le="+Inf"} 2
+test_request_size_bytes_sum{code="404",method="GET",operation="",route="unmatched"} 0
+test_request_size_bytes_count{code="404",method="GET",operation="",route="unmatched"} 2
+`), "test_request_size_bytes")
+	td.CmpNoError(t, err)
+}
@@ -0,0 +1,191 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func init() {
+	// GraphQL queries and GraphiQL page are described in the document
+	// with the media types the filter does not decode out of the box.
+	openapi3filter.RegisterBodyDecoder("application/graphql", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.PlainBodyDecoder)
+}
+
+// OpenAPIOptions holds options of the OpenAPIValidationMiddleware.
+type OpenAPIOptions struct {
+	// BasePath is the path prefix the document paths are served under, e.g. /v1.
+	// It overrides the servers section of the document.
+	BasePath string
+
+	// ValidateResponses enables validation of responses. Responses which do not
+	// match the document are logged and sent to the client unchanged.
+	ValidateResponses bool
+}
+
+// OpenAPIValidationMiddleware represents middleware which validates requests
+// against the given OpenAPI document: path params, query and body schema.
+// Invalid requests are rejected with 400 problem details response.
+// Requests to the paths which are not described by the document are passed through.
+func OpenAPIValidationMiddleware(
+	logger log.Logger, doc *openapi3.T, opts OpenAPIOptions,
+) (func(next http.Handler) http.Handler, error) {
+	// Shallow copy is enough, only the servers section is replaced.
+	d := *doc
+	d.Servers = openapi3.Servers{{URL: opts.BasePath}}
+
+	router, routerErr := gorillamux.NewRouter(&d)
+	if routerErr != nil {
+		return nil, fmt.Errorf("create openapi router: %w", routerErr)
+	}
+
+	filterOpts := openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(withoutTrailingSlash(r))
+			if err != nil {
+				if !errors.Is(err, routers.ErrPathNotFound) && !errors.Is(err, routers.ErrMethodNotAllowed) {
+					logger.Errorf("Failed to find openapi route for %s %s: %s", r.Method, r.URL.Path, err.Error())
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqInput := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &filterOpts,
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), reqInput); err != nil {
+				p := problem.New(http.StatusBadRequest, "request does not match the specification")
+				p.Instance = r.URL.Path
+				p.RequestID, _ = requestid.FromContext(r.Context())
+				p.Errors = toProblemErrors(err)
+
+				if err := problem.Write(w, p); err != nil {
+					logger.Errorf("failed to write problem %+v: %s", p, err.Error())
+				}
+
+				return
+			}
+
+			if !opts.ValidateResponses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body bytes.Buffer
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&body)
+
+			next.ServeHTTP(ww, r)
+
+			resInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: reqInput,
+				Status:                 ww.Status(),
+				Header:                 ww.Header(),
+				Body:                   io.NopCloser(&body),
+				Options: &openapi3filter.Options{
+					MultiError:            true,
+					IncludeResponseStatus: true,
+				},
+			}
+
+			if err := openapi3filter.ValidateResponse(r.Context(), resInput); err != nil {
+				logger.Errorf("Response of %s %s does not match the specification: %s", r.Method, r.URL.Path, err.Error())
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}, nil
+}
+
+// toProblemErrors flattens request validation errors into the list of problem errors.
+func toProblemErrors(err error) []problem.Error {
+	// Only the top level list is flattened here, errors.As would match
+	// the first RequestError of the list and drop the rest.
+	if multi, ok := err.(openapi3.MultiError); ok { //nolint: errorlint
+		errs := make([]problem.Error, 0, len(multi))
+		for _, e := range multi {
+			errs = append(errs, toProblemErrors(e)...)
+		}
+
+		return errs
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) {
+		pe := problem.Error{In: "body", Reason: reqErr.Error()}
+
+		if reqErr.Parameter != nil {
+			pe.In = reqErr.Parameter.In
+			pe.Name = reqErr.Parameter.Name
+		}
+
+		var schemaErrs openapi3.MultiError
+		if !errors.As(reqErr.Err, &schemaErrs) {
+			schemaErrs = openapi3.MultiError{reqErr.Err}
+		}
+
+		errs := make([]problem.Error, 0, len(schemaErrs))
+		for _, e := range schemaErrs {
+			var schemaErr *openapi3.SchemaError
+			if !errors.As(e, &schemaErr) {
+				errs = append(errs, pe)
+				continue
+			}
+
+			se := pe
+			se.Reason = schemaErr.Reason
+
+			if se.Name == "" {
+				se.Name = strings.Join(schemaErr.JSONPointer(), ".")
+			}
+
+			errs = append(errs, se)
+		}
+
+		return errs
+	}
+
+	return []problem.Error{{Reason: err.Error()}}
+}
+
+// withoutTrailingSlash returns a shallow copy of r without trailing slash in the URL path,
+// so the request can be matched against the document paths the same way chi does with StripSlashes.
+func withoutTrailingSlash(r *http.Request) *http.Request {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == r.URL.Path {
+		return r
+	}
+
+	u := *r.URL
+	u.Path = path
+	u.RawPath = ""
+
+	rr := *r
+	rr.URL = &u
+
+	return &rr
+}
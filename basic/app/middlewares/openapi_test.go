@@ -0,0 +1,198 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/static"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestOpenAPIValidationMiddleware(t *testing.T) {
+	type tcase struct {
+		method  string
+		path    string
+		payload string
+
+		wantStatus   int
+		wantBody     string
+		wantProblems []problem.Error
+	}
+
+	tests := map[string]tcase{
+		"Valid body": {
+			method:     http.MethodPost,
+			path:       "/v1/cat",
+			payload:    `{"name":"test","breed":"test-breed","age":10}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"name":"test","breed":"test-breed","age":10}`,
+		},
+		"Valid body trailing slash": {
+			method:     http.MethodPost,
+			path:       "/v1/cat/",
+			payload:    `{"name":"test"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"name":"test"}`,
+		},
+		"Missing required field": {
+			method:     http.MethodPost,
+			path:       "/v1/cat",
+			payload:    `{"breed":"test-breed"}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblems: []problem.Error{
+				{In: "body", Name: "name", Reason: `property "name" is missing`},
+			},
+		},
+		"Invalid field type": {
+			method:     http.MethodPost,
+			path:       "/v1/cat",
+			payload:    `{"name":"test","age":"ten"}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblems: []problem.Error{
+				{In: "body", Name: "age", Reason: `value must be an integer`},
+			},
+		},
+		"Several invalid fields": {
+			method:     http.MethodPost,
+			path:       "/v1/cat",
+			payload:    `{"name":"","age":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblems: []problem.Error{
+				{In: "body", Name: "name", Reason: `minimum string length is 1`},
+				{In: "body", Name: "age", Reason: `number must be at least 0`},
+			},
+		},
+		"GraphQL query": {
+			method:     http.MethodPost,
+			path:       "/v1/cat/graphql",
+			payload:    `{"query":"{}"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"query":"{}"}`,
+		},
+		"Not described path": {
+			method:     http.MethodPost,
+			path:       "/v1/dog",
+			payload:    `{"bark":true}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"bark":true}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := newOpenAPITestServer(t, log.DisabledLogger(), OpenAPIOptions{BasePath: "/v1"},
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusCreated)
+					io.Copy(w, r.Body) //nolint: errcheck
+				},
+			)
+
+			req, err := http.NewRequestWithContext(context.Background(),
+				tc.method, server.URL+tc.path, strings.NewReader(tc.payload))
+			td.CmpNoError(t, err)
+
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := http.DefaultClient.Do(req)
+			td.CmpNoError(t, err)
+
+			defer res.Body.Close()
+
+			td.Cmp(t, res.StatusCode, tc.wantStatus)
+
+			body, err := io.ReadAll(res.Body)
+			td.CmpNoError(t, err)
+
+			if tc.wantProblems == nil {
+				td.Cmp(t, string(body), tc.wantBody)
+				return
+			}
+
+			td.Cmp(t, res.Header.Get("Content-Type"), problem.ContentType)
+
+			var got problem.Details
+			td.CmpNoError(t, json.Unmarshal(body, &got))
+			td.Cmp(t, got.Status, http.StatusBadRequest)
+			td.Cmp(t, got.Errors, td.Bag(td.Flatten(tc.wantProblems)))
+		})
+	}
+}
+
+func TestOpenAPIValidationMiddleware_ValidateResponses(t *testing.T) {
+	logger := &recordLogger{}
+
+	server := newOpenAPITestServer(t, logger, OpenAPIOptions{BasePath: "/v1", ValidateResponses: true},
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"test-id"}`)) //nolint: errcheck
+		},
+	)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/v1/cat/test-id", nil)
+	td.CmpNoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	td.CmpNoError(t, err)
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	td.CmpNoError(t, err)
+
+	// Response is sent unchanged, the drift is only logged.
+	td.Cmp(t, res.StatusCode, http.StatusOK)
+	td.Cmp(t, string(body), `{"id":"test-id"}`)
+	td.Cmp(t, logger.errors, td.Len(1))
+	td.Cmp(t, logger.errors[0], td.Contains("GET /v1/cat/test-id does not match the specification"))
+}
+
+func newOpenAPITestServer(
+	t *testing.T, logger log.Logger, opts OpenAPIOptions, handler http.HandlerFunc,
+) *httptest.Server {
+	t.Helper()
+
+	data, err := static.OpenAPI()
+	td.CmpNoError(t, err)
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	td.CmpNoError(t, err)
+	td.CmpNoError(t, doc.Validate(context.Background()))
+
+	mw, err := OpenAPIValidationMiddleware(logger, doc, opts)
+	td.CmpNoError(t, err)
+
+	router := chi.NewRouter()
+	router.Route("/v1", func(v1 chi.Router) {
+		v1.Use(mw)
+		v1.HandleFunc("/*", handler)
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() { server.Close() })
+
+	return server
+}
+
+type recordLogger struct {
+	errors []string
+}
+
+func (*recordLogger) Debugf(string, ...any) {}
+func (*recordLogger) Infof(string, ...any)  {}
+func (*recordLogger) Warnf(string, ...any)  {}
+
+func (l *recordLogger) With(...log.Field) log.Logger { return l }
+
+func (l *recordLogger) Errorf(format string, args ...any) {
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RateLimitMiddleware represents middleware which limits requests of every client
+// by the limit of the matching rule, see ratelimit.DynamicRules to change them
+// while the server runs. Clients are told apart by the principal,
+// which covers API keys, and by the IP address when there is no principal, so
+// it should run after AuthMiddleware. Quota is reported by RateLimit-* headers,
+// see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers.
+// Requests over the quota are rejected with 429 problem details response
+// and Retry-After header, they are counted by a metric registered in reg.
+// Requests are let through if the store fails.
+func RateLimitMiddleware(
+	logger log.Logger, reg prometheus.Registerer, store ratelimit.Store, rules ratelimit.Matcher,
+) func(next http.Handler) http.Handler {
+	rejectedTotal := promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_rejected_requests_total",
+		Help: "Number of requests rejected by the rate limit of the client.",
+	}, []string{"method", "rule"})
+
+	return rateLimit(logger, rejectedTotal, store, rules, clientKey)
+}
+
+// IPRateLimitMiddleware represents middleware which limits requests of every IP address
+// by the limit of the matching rule. It should run before AuthMiddleware,
+// so requests with guessed API keys and tokens, which are rejected by the authentication,
+// are limited as well. It reports and rejects requests as RateLimitMiddleware does,
+// rejections are counted by a separate metric registered in reg.
+func IPRateLimitMiddleware(
+	logger log.Logger, reg prometheus.Registerer, store ratelimit.Store, rules ratelimit.Matcher,
+) func(next http.Handler) http.Handler {
+	rejectedTotal := promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_ip_rejected_requests_total",
+		Help: "Number of requests rejected by the rate limit of the IP address.",
+	}, []string{"method", "rule"})
+
+	return rateLimit(logger, rejectedTotal, store, rules, func(r *http.Request) string {
+		return "ip-limit|" + ipKey(r)
+	})
+}
+
+// rateLimit returns middleware which takes a token from the bucket of the matching rule and the key of the request.
+func rateLimit(
+	logger log.Logger, rejectedTotal *prometheus.CounterVec, store ratelimit.Store, rules ratelimit.Matcher,
+	key func(r *http.Request) string,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			rule, limit := rules.Match(r.Method, r.URL.Path)
+			if limit.IsZero() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := store.Take(r.Context(), rule+"|"+key(r), limit)
+			if err != nil {
+				logger.Errorf("Failed to take rate limit token: %s", err.Error())
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(ceilSeconds(limit.Period)))
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				rejectedTotal.WithLabelValues(r.Method, rule).Inc()
+
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				writeProblem(w, r, http.StatusTooManyRequests, "rate limit exceeded, retry later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// clientKey returns the key of the bucket of the request client.
+func clientKey(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return "principal:" + p.Subject
+	}
+
+	return ipKey(r)
+}
+
+// ipKey returns the key of the bucket of the request IP address.
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int { return int(math.Ceil(d.Seconds())) }
@@ -0,0 +1,138 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/ratelimit"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	rules := ratelimit.Rules{
+		Default: ratelimit.Limit{Requests: 2, Period: time.Minute},
+		Routes: []ratelimit.Route{
+			{Method: "POST", Prefix: "/v1/cat", Limit: ratelimit.Limit{Requests: 1, Period: time.Minute}},
+			{Method: "*", Prefix: "/v1/cat/unlimited", Limit: ratelimit.Limit{}},
+		},
+	}
+
+	handler := RateLimitMiddleware(log.DisabledLogger(), prometheus.NewRegistry(), ratelimit.NewMemoryStore(), rules)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	do := func(method, path, subject, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		req.RemoteAddr = remoteAddr
+		if subject != "" {
+			req = req.WithContext(auth.ContextWithPrincipal(req.Context(), &auth.Principal{Subject: subject}))
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Default limit", func(t *testing.T) {
+		rec := do(http.MethodGet, "/v1/cat/1", "user-1", "10.0.0.1:1234")
+		td.Cmp(t, rec.Code, http.StatusOK)
+		td.Cmp(t, rec.Header().Get("RateLimit-Policy"), "2;w=60")
+		td.Cmp(t, rec.Header().Get("RateLimit-Limit"), "2")
+		td.Cmp(t, rec.Header().Get("RateLimit-Remaining"), "1")
+		td.Cmp(t, rec.Header().Get("RateLimit-Reset"), "30")
+
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "user-1", "10.0.0.1:1234").Code, http.StatusOK)
+
+		rec = do(http.MethodGet, "/v1/cat/2", "user-1", "10.0.0.2:1234")
+		td.Cmp(t, rec.Code, http.StatusTooManyRequests)
+		td.Cmp(t, rec.Header().Get("Retry-After"), "30")
+		td.Cmp(t, rec.Header().Get("RateLimit-Remaining"), "0")
+		td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+
+		// Other principals have their own quota.
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "user-2", "10.0.0.1:1234").Code, http.StatusOK)
+	})
+
+	t.Run("Route limit", func(t *testing.T) {
+		td.Cmp(t, do(http.MethodPost, "/v1/cat", "user-3", "").Code, http.StatusOK)
+		td.Cmp(t, do(http.MethodPost, "/v1/cat", "user-3", "").Code, http.StatusTooManyRequests)
+
+		// Routes have their own quota.
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "user-3", "").Code, http.StatusOK)
+	})
+
+	t.Run("IP address", func(t *testing.T) {
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "", "10.0.0.3:1").Code, http.StatusOK)
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "", "10.0.0.3:2").Code, http.StatusOK)
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "", "10.0.0.3:3").Code, http.StatusTooManyRequests)
+		td.Cmp(t, do(http.MethodGet, "/v1/cat/1", "", "10.0.0.4:1").Code, http.StatusOK)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		for range 3 {
+			rec := do(http.MethodGet, "/v1/cat/unlimited", "user-4", "")
+			td.Cmp(t, rec.Code, http.StatusOK)
+			td.Cmp(t, rec.Header().Get("RateLimit-Limit"), "")
+		}
+	})
+
+	t.Run("Store failure", func(t *testing.T) {
+		handler := RateLimitMiddleware(log.DisabledLogger(), prometheus.NewRegistry(), failingStore{}, rules)(
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+		)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/cat/1", http.NoBody))
+		td.Cmp(t, rec.Code, http.StatusOK)
+	})
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	rules := ratelimit.Rules{Default: ratelimit.Limit{Requests: 2, Period: time.Minute}}
+
+	// The requests are limited before the authentication rejects them.
+	handler := IPRateLimitMiddleware(log.DisabledLogger(), prometheus.NewRegistry(), ratelimit.NewMemoryStore(), rules)(
+		AuthMiddleware(log.DisabledLogger(), auth.NewHeaderExtractor())(
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+		),
+	)
+
+	do := func(subject, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/cat/1", http.NoBody)
+		req.RemoteAddr = remoteAddr
+		if subject != "" {
+			req.Header.Set(auth.SubjectHeader, subject)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	td.Cmp(t, do("", "10.0.0.1:1").Code, http.StatusUnauthorized)
+	td.Cmp(t, do("", "10.0.0.1:2").Code, http.StatusUnauthorized)
+
+	rec := do("user-1", "10.0.0.1:3")
+	td.Cmp(t, rec.Code, http.StatusTooManyRequests)
+	td.Cmp(t, rec.Header().Get("Retry-After"), "30")
+	td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+
+	// Other addresses have their own quota.
+	td.Cmp(t, do("user-1", "10.0.0.2:1").Code, http.StatusOK)
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store is down")
+}
@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIPMiddleware represents middleware which replaces the remote address of requests
+// coming from the trusted proxies with the client address from X-Forwarded-For header.
+// The header is read from right to left, every proxy appends the address of its peer,
+// the first address which is not trusted is the client. The header of requests from
+// other peers is ignored, since anyone can send it.
+func RealIPMiddleware(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		addr = addr.Unmap()
+		for _, p := range trusted {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			peer, err := remoteAddr(r)
+			if err != nil || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Every header line is a separate list, the last line is appended by the nearest proxy.
+			hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break // A malformed hop can not be trusted, neither any hop before it.
+				}
+
+				r.RemoteAddr = addr.Unmap().String()
+
+				if !isTrusted(addr) {
+					break
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// remoteAddr returns the address of the peer which sent the request.
+func remoteAddr(r *http.Request) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return netip.ParseAddr(host)
+}
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges of trusted proxies.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, err
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes = append(prefixes, p.Masked())
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+	td.CmpNoError(t, err)
+
+	type tcase struct {
+		remoteAddr     string
+		forwardedFor   []string
+		wantRemoteAddr string
+	}
+
+	tests := map[string]tcase{
+		"Trusted proxy": {
+			remoteAddr:     "10.1.2.3:5555",
+			forwardedFor:   []string{"203.0.113.7"},
+			wantRemoteAddr: "203.0.113.7",
+		},
+		"Chain of trusted proxies": {
+			remoteAddr:     "10.1.2.3:5555",
+			forwardedFor:   []string{"203.0.113.7, 192.168.1.1", "10.0.0.1"},
+			wantRemoteAddr: "203.0.113.7",
+		},
+		"Spoofed hops before the client": {
+			remoteAddr:     "10.1.2.3:5555",
+			forwardedFor:   []string{"1.1.1.1, 203.0.113.7"},
+			wantRemoteAddr: "203.0.113.7",
+		},
+		"IPv6": {
+			remoteAddr:     "[fd00::1]:5555",
+			forwardedFor:   []string{"2001:db8::1"},
+			wantRemoteAddr: "2001:db8::1",
+		},
+		"Untrusted peer": {
+			remoteAddr:     "203.0.113.9:5555",
+			forwardedFor:   []string{"1.1.1.1"},
+			wantRemoteAddr: "203.0.113.9:5555",
+		},
+		"Malformed hop": {
+			remoteAddr:     "10.1.2.3:5555",
+			forwardedFor:   []string{"1.1.1.1, unknown"},
+			wantRemoteAddr: "10.1.2.3:5555",
+		},
+		"No header": {
+			remoteAddr:     "10.1.2.3:5555",
+			wantRemoteAddr: "10.1.2.3:5555",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got string
+
+			handler := RealIPMiddleware(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			td.Cmp(t, got, tc.wantRemoteAddr)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/33"})
+	td.CmpError(t, err)
+
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	td.CmpError(t, err)
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMiddleware represents middleware which takes the request ID from the X-Request-ID
+// header or generates a new one if the header is missing or invalid. The ID is echoed
+// in the response header and put into the request context, see requestid.FromContext.
+func RequestIDMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+
+			w.Header().Set(requestid.Header, id)
+
+			next.ServeHTTP(w, r.WithContext(requestid.ContextWithID(r.Context(), id)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// GRPCRequestIDInterceptor takes the request ID of unary gRPC calls from the x-request-id
+// metadata or generates a new one. The ID is sent back in the response header
+// and put into the call context, see requestid.FromContext.
+func GRPCRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestid.Header); len(values) > 0 {
+				id = values[0]
+			}
+		}
+
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(requestid.Header), id)) //nolint: errcheck
+
+		return handler(requestid.ContextWithID(ctx, id), req)
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idkit"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	type tcase struct {
+		header string
+
+		wantID string
+	}
+
+	generated := td.Code(func(id string) bool { return idkit.ValidateULID(id) == nil })
+
+	tests := map[string]tcase{
+		"Given ID":   {header: "0b6f6a3e-62b0-4bd5-9f0a-2b9d1c1f6b2e", wantID: "0b6f6a3e-62b0-4bd5-9f0a-2b9d1c1f6b2e"},
+		"Missing ID": {},
+		"Invalid ID": {header: "<script>"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got string
+
+			handler := RequestIDMiddleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got, _ = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tc.header != "" {
+				req.Header.Set(requestid.Header, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tc.wantID != "" {
+				td.Cmp(t, got, tc.wantID)
+			} else {
+				td.Cmp(t, got, generated)
+			}
+
+			td.Cmp(t, rec.Header().Get(requestid.Header), got)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultContentSecurityPolicy forbids everything, which suits API responses.
+const DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// SecurityHeadersOptions configures SecurityHeadersMiddleware.
+type SecurityHeadersOptions struct {
+	// HSTSMaxAge is the time browsers must use HTTPS only for, zero disables HSTS.
+	// Enable it only if the API is served over HTTPS, e.g. by TLS terminating proxy.
+	HSTSMaxAge time.Duration
+
+	// ContentSecurityPolicy is the policy of responses, DefaultContentSecurityPolicy if empty.
+	ContentSecurityPolicy string
+
+	// PageContentSecurityPolicies overrides the policy of responses by the path prefix,
+	// the longest matching prefix wins. HTML pages, e.g. API docs, need their scripts.
+	PageContentSecurityPolicies map[string]string
+}
+
+// SecurityHeadersMiddleware represents middleware which sets headers
+// hardening browsers against sniffing, framing and downgrade attacks.
+// More about the headers: https://owasp.org/www-project-secure-headers
+func SecurityHeadersMiddleware(opts SecurityHeadersOptions) func(next http.Handler) http.Handler {
+	if opts.ContentSecurityPolicy == "" {
+		opts.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	}
+
+	hsts := "max-age=" + strconv.Itoa(int(opts.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+
+			if opts.HSTSMaxAge > 0 {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			h.Set("Content-Security-Policy", pagePolicy(opts, r.URL.Path))
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// pagePolicy returns the content security policy of the given path.
+func pagePolicy(opts SecurityHeadersOptions, path string) string {
+	policy, matched := opts.ContentSecurityPolicy, -1
+
+	for prefix, p := range opts.PageContentSecurityPolicies {
+		if strings.HasPrefix(path, prefix) && len(prefix) > matched {
+			policy, matched = p, len(prefix)
+		}
+	}
+
+	return policy
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+)
+
+// TenantMiddleware represents middleware which resolves the tenant of requests
+// by the given resolver and puts it into the request context, see tenant.FromContext.
+// It must run after AuthMiddleware, since the tenant of the principal wins.
+// Requests selecting a foreign tenant are rejected with 403, requests
+// without a tenant or with a malformed one with 400 problem details response.
+func TenantMiddleware(resolver *tenant.Resolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id, err := resolver.Resolve(r.Context(), r.Header)
+			if err != nil {
+				status, detail := http.StatusBadRequest, "missing or invalid "+tenant.Header+" header"
+				if errors.Is(err, tenant.ErrMismatch) {
+					status, detail = http.StatusForbidden, "the tenant is not accessible by the caller"
+				}
+
+				writeProblem(w, r, status, detail)
+				return
+			}
+
+			ctx := log.ContextWith(r.Context(), log.String("tenant", id))
+
+			next.ServeHTTP(w, r.WithContext(tenant.ContextWithID(ctx, id)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	handler := TenantMiddleware(tenant.NewResolver(""))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := tenant.FromContext(r.Context())
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte(id)) //nolint: errcheck
+	}))
+
+	type tcase struct {
+		principal *auth.Principal
+		header    string
+
+		wantStatus int
+		wantBody   string
+	}
+
+	tests := map[string]tcase{
+		"Principal tenant": {
+			principal:  &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			wantStatus: http.StatusOK,
+			wantBody:   "acme",
+		},
+		"Header tenant": {
+			principal:  &auth.Principal{Subject: "service", Scopes: []string{tenant.CrossTenantScope}},
+			header:     "globex",
+			wantStatus: http.StatusOK,
+			wantBody:   "globex",
+		},
+		"Header tenant without cross-tenant scope": {
+			principal:  &auth.Principal{Subject: "service"},
+			header:     "globex",
+			wantStatus: http.StatusForbidden,
+		},
+		"Foreign tenant": {
+			principal:  &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			header:     "globex",
+			wantStatus: http.StatusForbidden,
+		},
+		"Missing tenant": {
+			principal:  &auth.Principal{Subject: "service"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cat/test-id", http.NoBody)
+			req = req.WithContext(auth.ContextWithPrincipal(req.Context(), tc.principal))
+			if tc.header != "" {
+				req.Header.Set(tenant.Header, tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			td.Cmp(t, rec.Code, tc.wantStatus)
+
+			if tc.wantStatus == http.StatusOK {
+				td.Cmp(t, rec.Body.String(), tc.wantBody)
+				return
+			}
+
+			td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+
+			var p problem.Details
+			td.CmpNoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+			td.Cmp(t, p.Status, tc.wantStatus)
+			td.Cmp(t, p.Instance, "/v1/cat/test-id")
+		})
+	}
+}
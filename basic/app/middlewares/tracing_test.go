@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maxatome/go-testdeep/td"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var handlerTraceID string
+
+	router := chi.NewRouter()
+	router.Use(TracingMiddleware())
+	router.Get("/v1/cat/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceID = trace.SpanContextFromContext(r.Context()).TraceID().String()
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/cat/42", http.NoBody)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	td.Cmp(t, handlerTraceID, traceID, "the trace of the client is continued")
+	td.Cmp(t, rec.Header().Get("traceparent"), td.HasPrefix("00-"+traceID+"-"))
+
+	spans := recorder.Ended()
+	td.Cmp(t, spans, td.Len(1))
+	td.Cmp(t, spans[0].Name(), "GET /v1/cat/{id}")
+	td.Cmp(t, spans[0].Parent().SpanID().String(), "00f067aa0ba902b7")
+	td.Cmp(t, spans[0].SpanKind(), trace.SpanKindServer)
+}
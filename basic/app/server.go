@@ -2,136 +2,464 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"net/http"
+	"net/netip"
+	"sync/atomic"
 	"time"
 
 	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/lifecycle"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/sync/errgroup"
+	"github.com/oasdiff/yaml"
+	"github.com/swaggest/swgui/v5emb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
-	// readTimeout represents default read timeout for the http.Server.
-	readTimeout = 10 * time.Second
+	// DefaultReadTimeout represents default read timeout for the http.Server.
+	DefaultReadTimeout = 10 * time.Second
 
-	// readHeaderTimeout represents default read header timeout for http.Server.
-	readHeaderTimeout = 5 * time.Second
+	// DefaultReadHeaderTimeout represents default read header timeout for http.Server.
+	DefaultReadHeaderTimeout = 5 * time.Second
 
-	// writeTimeout represents default write timeout for the http.Server.
-	writeTimeout = 10 * time.Second
+	// DefaultWriteTimeout represents default write timeout for the http.Server.
+	DefaultWriteTimeout = 10 * time.Second
 
-	// idleTimeout represents default idle timeout for the http.Server.
-	idleTimeout = 90 * time.Second
+	// DefaultIdleTimeout represents default idle timeout for the http.Server.
+	DefaultIdleTimeout = 90 * time.Second
 
-	// shutdownTimeout represents server default shutdown timeout.
-	shutdownTimeout = 5 * time.Second
+	// DefaultShutdownTimeout represents server default shutdown timeout.
+	DefaultShutdownTimeout = 5 * time.Second
 )
 
+// Content security policies of the HTML pages served along with the API.
+const (
+	// docsContentSecurityPolicy allows Swagger UI, which inlines its bootstrap code.
+	docsContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; " +
+		"style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+	// graphiQLContentSecurityPolicy allows GraphiQL, which loads its code from jsDelivr.
+	graphiQLContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline' cdn.jsdelivr.net; " +
+		"style-src 'self' 'unsafe-inline' cdn.jsdelivr.net; img-src 'self' data:; frame-ancestors 'none'"
+)
+
+// HTTPOptions configures the browser facing behaviour of the HTTP listener.
+type HTTPOptions struct {
+	// CORS configures cross-origin requests, they are disabled by default.
+	CORS middlewares.CORSOptions
+
+	// SecurityHeaders configures security headers of every response.
+	// Policies of the API docs and GraphiQL pages are added to the given ones.
+	SecurityHeaders middlewares.SecurityHeadersOptions
+
+	// TrustedProxies lists proxies whose X-Forwarded-For header tells the client address.
+	TrustedProxies []netip.Prefix
+
+	// Metrics configures the metrics of /v1 requests, which are registered in AdminOptions.Metrics.
+	Metrics middlewares.MetricsOptions
+
+	// DisableDocs stops serving the OpenAPI document and Swagger UI, see Server.SetDocs.
+	DisableDocs bool
+
+	// Timeouts of the HTTP listener, the defaults are used if zero.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownTimeout limits the graceful shutdown of the listeners, DefaultShutdownTimeout is used if zero.
+	ShutdownTimeout time.Duration
+}
+
+// withDefaults returns a copy of the options with the default timeouts set instead of zero ones.
+func (o HTTPOptions) withDefaults() HTTPOptions {
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+
+	if o.ReadHeaderTimeout <= 0 {
+		o.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+
+	if o.ShutdownTimeout <= 0 {
+		o.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	return o
+}
+
+// GRPCService represents a gRPC transport which is able
+// to register itself on a gRPC server.
+type GRPCService interface {
+	Register(s grpc.ServiceRegistrar)
+}
+
+// ServerOptions holds the dependencies and options of the Server.
+type ServerOptions struct {
+	// HTTPAddr and GRPCAddr are the addresses of the API listeners.
+	HTTPAddr string
+	GRPCAddr string
+
+	Logger log.Logger
+
+	// TLSConfig makes both API listeners serve TLS if not nil, e.g. certs.Reloader.TLSConfig,
+	// the identity of clients authenticated by certificates is put into the context.
+	TLSConfig *tls.Config
+
+	// OpenAPI is the document of the API served along with it.
+	OpenAPI *openapi3.T
+
+	// Cat is the HTTP transport of the cat service mounted under /v1/cat,
+	// CatRPC is its gRPC transport.
+	Cat    http.Handler
+	CatRPC GRPCService
+
+	// HTTP applies to every HTTP route, see HTTPOptions.
+	HTTP HTTPOptions
+
+	// Admin configures the listener of probes, metrics and profiles, see AdminOptions.
+	Admin AdminOptions
+
+	// GRPCInterceptors are applied to every gRPC call after recovery and logging interceptors.
+	GRPCInterceptors []grpc.UnaryServerInterceptor
+
+	// V1Middlewares are applied to every /v1 route after logging and metrics middlewares.
+	V1Middlewares []func(next http.Handler) http.Handler
+}
+
 // Server holds all dependencies for providing
-// an HTTP transport functionality.
+// an HTTP and gRPC transport functionality.
 type Server struct {
-	router chi.Router
-	server *http.Server
-	logger log.Logger
+	router  chi.Router
+	server  *http.Server
+	logger  log.Logger
+	openAPI *openapi3.T
+
+	grpcAddr   string
+	grpcServer *grpc.Server
+
+	admin     *http.Server
+	adminOpts AdminOptions
+
+	// ready is set while the listeners serve and unset as soon as the shutdown starts.
+	ready atomic.Bool
+
+	// docs is set while the API documentation is served, see SetDocs.
+	docs atomic.Bool
+
+	shutdownTimeout time.Duration
 }
 
-func NewServer(addr string, logger log.Logger, cat http.Handler) *Server {
+// NewServer returns a pointer to a new instance of Server, see ServerOptions.
+// Probes, metrics and profiles are served by a separate listener, see AdminOptions.
+func NewServer(opts ServerOptions) *Server {
 	router := chi.NewRouter()
+	httpOpts := opts.HTTP.withDefaults()
+	adminOpts := opts.Admin
+
+	if adminOpts.Metrics == nil {
+		adminOpts.Metrics = metrics.NewRegistry()
+	}
+
+	grpcCreds := insecure.NewCredentials()
+	if opts.TLSConfig != nil {
+		grpcCreds = credentials.NewTLS(opts.TLSConfig)
+	}
 
 	s := Server{
-		logger: logger,
-		router: router,
+		logger:    opts.Logger,
+		router:    router,
+		openAPI:   opts.OpenAPI,
+		grpcAddr:  opts.GRPCAddr,
+		adminOpts: adminOpts,
+
+		shutdownTimeout: httpOpts.ShutdownTimeout,
+		grpcServer: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				middlewares.GRPCRequestIDInterceptor(),
+				middlewares.GRPCRecoveryInterceptor(opts.Logger),
+				middlewares.GRPCLoggingInterceptor(opts.Logger),
+				middlewares.GRPCClientCertInterceptor(),
+			),
+			grpc.ChainUnaryInterceptor(opts.GRPCInterceptors...),
+			grpc.Creds(grpcCreds),
+			grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		),
 		server: &http.Server{
-			Addr:              addr,
+			Addr:              opts.HTTPAddr,
 			Handler:           router,
-			ReadTimeout:       readTimeout,
-			ReadHeaderTimeout: readHeaderTimeout,
-			WriteTimeout:      writeTimeout,
-			IdleTimeout:       idleTimeout,
+			TLSConfig:         opts.TLSConfig,
+			ReadTimeout:       httpOpts.ReadTimeout,
+			ReadHeaderTimeout: httpOpts.ReadHeaderTimeout,
+			WriteTimeout:      httpOpts.WriteTimeout,
+			IdleTimeout:       httpOpts.IdleTimeout,
 		},
 	}
 
+	securityHeaders := httpOpts.SecurityHeaders
+
+	securityHeaders.PageContentSecurityPolicies = map[string]string{
+		"/docs":           docsContentSecurityPolicy,
+		"/v1/cat/graphql": graphiQLContentSecurityPolicy,
+	}
+	maps.Copy(securityHeaders.PageContentSecurityPolicies, httpOpts.SecurityHeaders.PageContentSecurityPolicies)
+
 	router.Use(
+		// The request ID and the span are set first, so even responses of recovered panics carry them.
+		middlewares.RequestIDMiddleware(),
+		middlewares.TracingMiddleware(),
 		middleware.Recoverer,
+		middlewares.ClientCertMiddleware(),
+		middlewares.RealIPMiddleware(httpOpts.TrustedProxies),
+		middlewares.SecurityHeadersMiddleware(securityHeaders),
+		middlewares.CORSMiddleware(httpOpts.CORS),
 		middleware.StripSlashes,
 	)
 
-	router.Get("/health", s.healthCheck)
-	router.Get("/metrics", s.metrics)
+	// API documentation.
+	s.docs.Store(!httpOpts.DisableDocs)
+
+	router.Group(func(docs chi.Router) {
+		docs.Use(s.docsEnabled)
+
+		docs.Get("/openapi.json", s.openAPIJSON)
+		docs.Get("/openapi.yaml", s.openAPIYAML)
+		docs.Mount("/docs", v5emb.New(opts.OpenAPI.Info.Title, "/openapi.json", "/docs"))
+	})
 
 	router.Route("/v1", func(v1 chi.Router) {
 		v1.Use(
 			middlewares.LoggingMiddleware(s.logger),
-			middlewares.MetricsMiddleware(),
+			middlewares.MetricsMiddleware(adminOpts.Metrics, httpOpts.Metrics),
 		)
+		v1.Use(opts.V1Middlewares...)
 
-		v1.Mount("/cat", cat)
+		v1.Mount("/cat", opts.Cat)
 	})
 
+	opts.CatRPC.Register(s.grpcServer)
+
+	s.admin = &http.Server{
+		Addr:              adminOpts.Addr,
+		Handler:           s.newAdminRouter(),
+		ReadHeaderTimeout: httpOpts.ReadHeaderTimeout,
+		IdleTimeout:       httpOpts.IdleTimeout,
+	}
+
 	return &s
 }
 
-// Serve listen to incoming connections and serves each request.
-func (s *Server) Serve(ctx context.Context) error {
+// Register registers the listeners with the lifecycle manager. The listeners are bound by their start hooks,
+// the app reports ready once all hooks are started and not ready as soon as the shutdown starts.
+// The API listeners are drained in lifecycle.PhaseDrain, within the shutdown timeout of HTTPOptions,
+// the admin listener serves probes and metrics until lifecycle.PhaseFinal.
+func (s *Server) Register(m *lifecycle.Manager) error {
 	if s.server.Addr == "" {
 		return fmt.Errorf("invalid listener address: %s", s.server.Addr)
 	}
 
-	g, serveCtx := errgroup.WithContext(ctx)
+	if s.grpcAddr == "" {
+		return fmt.Errorf("invalid gRPC listener address: %s", s.grpcAddr)
+	}
 
-	// handle shutdown signal in the background.
-	g.Go(func() error { return s.handleShutdown(serveCtx) })
+	if s.admin.Addr == "" {
+		return fmt.Errorf("invalid admin listener address: %s", s.admin.Addr)
+	}
 
-	g.Go(func() error {
-		s.logger.Infof("ListenerHTTP started to listen on: %s", s.server.Addr)
+	var httpListener, grpcListener, adminListener net.Listener
+
+	m.Append(
+		lifecycle.Hook{
+			Name:  "admin listener",
+			Phase: lifecycle.PhaseFinal,
+			Start: func(context.Context) (err error) {
+				adminListener, err = net.Listen("tcp", s.admin.Addr)
+				if err != nil {
+					return fmt.Errorf("admin listener: %w", err)
+				}
+
+				s.logger.Infof("ListenerAdmin started to listen on: %s", s.admin.Addr)
+
+				return nil
+			},
+			Run: func(context.Context) error {
+				if err := s.admin.Serve(adminListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return fmt.Errorf("admin listener failed: %w", err)
+				}
+
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				if err := s.admin.Shutdown(ctx); err != nil {
+					return fmt.Errorf("failed to shutdown the admin listener gracefully: %w", err)
+				}
+
+				return nil
+			},
+		},
+		lifecycle.Hook{
+			Name:  "http listener",
+			Phase: lifecycle.PhaseDrain,
+			Start: func(context.Context) (err error) {
+				httpListener, err = net.Listen("tcp", s.server.Addr)
+				if err != nil {
+					return fmt.Errorf("listener: %w", err)
+				}
+
+				s.logger.Infof("ListenerHTTP started to listen on: %s", s.server.Addr)
+
+				return nil
+			},
+			Run: func(context.Context) error {
+				serve := s.server.Serve
+				if s.server.TLSConfig != nil {
+					// Certificates are served by the TLS config, so no files are given.
+					serve = func(l net.Listener) error { return s.server.ServeTLS(l, "", "") }
+				}
+
+				if err := serve(httpListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return fmt.Errorf("listener failed: %w", err)
+				}
+
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+				defer cancel()
+
+				if err := s.server.Shutdown(ctx); err != nil {
+					return fmt.Errorf("failed to shutdown the listener gracefully: %w", err)
+				}
+
+				return nil
+			},
+		},
+		lifecycle.Hook{
+			Name:  "grpc listener",
+			Phase: lifecycle.PhaseDrain,
+			Start: func(context.Context) (err error) {
+				grpcListener, err = net.Listen("tcp", s.grpcAddr)
+				if err != nil {
+					return fmt.Errorf("gRPC listener: %w", err)
+				}
+
+				s.logger.Infof("ListenerGRPC started to listen on: %s", s.grpcAddr)
+
+				return nil
+			},
+			Run: func(context.Context) error {
+				if err := s.grpcServer.Serve(grpcListener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					return fmt.Errorf("gRPC listener failed: %w", err)
+				}
+
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+				defer cancel()
+
+				return s.shutdownGRPC(ctx)
+			},
+		},
+		lifecycle.Hook{
+			Name:  "readiness",
+			Phase: lifecycle.PhaseNotReady,
+			Start: func(context.Context) error {
+				s.ready.Store(true)
+				return nil
+			},
+			Stop: func(context.Context) error {
+				s.ready.Store(false)
+				return nil
+			},
+		},
+	)
 
-		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return fmt.Errorf("listener failed: %w", err)
-		}
+	return nil
+}
 
-		return nil
-	})
+// SetDocs enables or disables the API documentation while the server runs.
+func (s *Server) SetDocs(enabled bool) {
+	s.docs.Store(enabled)
+}
 
-	if err := g.Wait(); err != nil {
-		s.logger.Errorf("Server failed: %s", err.Error())
+// docsEnabled responds with 404 to the documentation requests while the documentation is disabled.
+func (s *Server) docsEnabled(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !s.docs.Load() {
+			http.NotFound(w, r)
+			return
+		}
 
-		return err
+		next.ServeHTTP(w, r)
 	}
 
-	s.logger.Infof("Bye!")
-
-	return nil
+	return http.HandlerFunc(fn)
 }
 
-func (*Server) metrics(w http.ResponseWriter, r *http.Request) {
-	promhttp.Handler().ServeHTTP(w, r)
+func (s *Server) openAPIJSON(w http.ResponseWriter, _ *http.Request) {
+	doc, err := json.Marshal(s.openAPI)
+	if err != nil {
+		s.logger.Errorf("failed to encode openapi document to json: %s", err.Error())
+
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(doc) //nolint: errcheck
 }
 
-func (*Server) healthCheck(w http.ResponseWriter, _ *http.Request) {
-	w.WriteHeader(http.StatusOK)
+func (s *Server) openAPIYAML(w http.ResponseWriter, _ *http.Request) {
+	doc, err := yaml.Marshal(s.openAPI)
+	if err != nil {
+		s.logger.Errorf("failed to encode openapi document to yaml: %s", err.Error())
+
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(doc) //nolint: errcheck
 }
 
-// handleShutdown blocks until select statement receives a signal from
-// ctx.Done, after that new context.WithTimeout will be created and passed to
-// http.Server Shutdown method.
-//
-// If Shutdown method returns non nil error, program will panic immediately.
-func (s *Server) handleShutdown(ctx context.Context) error {
-	<-ctx.Done()
+// shutdownGRPC stops the gRPC server gracefully, waiting for pending RPCs
+// to finish. If ctx is done before that, the server is stopped forcibly.
+func (s *Server) shutdownGRPC(ctx context.Context) error {
+	stopped := make(chan struct{})
 
-	s.logger.Infof("Shutting down the listener!")
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
 
-	if err := s.server.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("failed to shutdown the listener gracefully: %w", err)
+		return fmt.Errorf("failed to shutdown the gRPC listener gracefully: %w", ctx.Err())
 	}
-
-	return nil
 }
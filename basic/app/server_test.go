@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/static"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/lifecycle"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/maxatome/go-testdeep/td"
+	"google.golang.org/grpc"
+)
+
+func TestServer_docs(t *testing.T) {
+	type tcase struct {
+		path string
+
+		wantStatus      int
+		wantContentType string
+		wantBody        td.TestDeep
+	}
+
+	tests := map[string]tcase{
+		"openapi.json": {
+			path:            "/openapi.json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        td.Contains(`"openapi":"3.0.3"`),
+		},
+		"openapi.yaml": {
+			path:            "/openapi.yaml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/yaml",
+			wantBody:        td.Contains("openapi: 3.0.3"),
+		},
+		"docs": {
+			path:            "/docs",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html",
+			wantBody:        td.Contains("/openapi.json"),
+		},
+		"docs assets": {
+			path:            "/docs/swagger-ui-bundle.js",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/javascript",
+			wantBody:        td.NotEmpty(),
+		},
+	}
+
+	server := httptest.NewServer(newTestServer(t, HTTPOptions{}).router)
+	t.Cleanup(func() { server.Close() })
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+tc.path, nil)
+			td.CmpNoError(t, err)
+
+			res, err := http.DefaultClient.Do(req)
+			td.CmpNoError(t, err)
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			td.CmpNoError(t, err)
+
+			td.Cmp(t, res.StatusCode, tc.wantStatus)
+			td.Cmp(t, res.Header.Get("Content-Type"), tc.wantContentType)
+			td.Cmp(t, string(body), tc.wantBody)
+		})
+	}
+}
+
+func TestServer_docsDisabled(t *testing.T) {
+	s := newTestServer(t, HTTPOptions{DisableDocs: true})
+
+	status := func(path string) int {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec.Code
+	}
+
+	for _, path := range []string{"/openapi.json", "/openapi.yaml", "/docs"} {
+		td.Cmp(t, status(path), http.StatusNotFound, path)
+	}
+
+	s.SetDocs(true)
+	td.Cmp(t, status("/openapi.json"), http.StatusOK)
+
+	s.SetDocs(false)
+	td.Cmp(t, status("/openapi.json"), http.StatusNotFound)
+}
+
+func TestServer_browserHeaders(t *testing.T) {
+	proxies, err := middlewares.ParseTrustedProxies([]string{"127.0.0.1"})
+	td.CmpNoError(t, err)
+
+	s := newTestServer(t, HTTPOptions{
+		CORS: middlewares.CORSOptions{
+			AllowedOrigins: []string{"https://app.example.com"},
+			MaxAge:         time.Hour,
+		},
+		SecurityHeaders: middlewares.SecurityHeadersOptions{HSTSMaxAge: 24 * time.Hour},
+		TrustedProxies:  proxies,
+	})
+
+	var remoteAddr string
+
+	s.router.Get("/remote-addr", func(_ http.ResponseWriter, r *http.Request) { remoteAddr = r.RemoteAddr })
+
+	server := httptest.NewServer(s.router)
+	t.Cleanup(func() { server.Close() })
+
+	do := func(method, path string, header http.Header) *http.Response {
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL+path, nil)
+		td.CmpNoError(t, err)
+
+		for name, values := range header {
+			req.Header[name] = values
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		td.CmpNoError(t, err)
+
+		res.Body.Close()
+
+		return res
+	}
+
+	t.Run("Security headers", func(t *testing.T) {
+		res := do(http.MethodGet, "/openapi.json", nil)
+		td.Cmp(t, res.Header.Get("Strict-Transport-Security"), "max-age=86400; includeSubDomains")
+		td.Cmp(t, res.Header.Get("X-Content-Type-Options"), "nosniff")
+		td.Cmp(t, res.Header.Get("X-Frame-Options"), "DENY")
+		td.Cmp(t, res.Header.Get("Content-Security-Policy"), middlewares.DefaultContentSecurityPolicy)
+
+		td.Cmp(t, do(http.MethodGet, "/docs", nil).Header.Get("Content-Security-Policy"), docsContentSecurityPolicy)
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		res := do(http.MethodOptions, "/v1/cat", http.Header{
+			"Origin":                         {"https://app.example.com"},
+			"Access-Control-Request-Method":  {http.MethodPost},
+			"Access-Control-Request-Headers": {"authorization, content-type"},
+		})
+
+		td.Cmp(t, res.StatusCode, http.StatusNoContent)
+		td.Cmp(t, res.Header.Get("Access-Control-Allow-Origin"), "https://app.example.com")
+		td.Cmp(t, res.Header.Get("Access-Control-Allow-Methods"), td.Contains(http.MethodPost))
+		td.Cmp(t, res.Header.Get("Access-Control-Max-Age"), "3600")
+	})
+
+	t.Run("Real IP", func(t *testing.T) {
+		do(http.MethodGet, "/remote-addr", http.Header{"X-Forwarded-For": {"203.0.113.7, 127.0.0.1"}})
+		td.Cmp(t, remoteAddr, "203.0.113.7")
+	})
+}
+
+func TestServer_Register(t *testing.T) {
+	s := newTestServer(t, HTTPOptions{})
+
+	m := lifecycle.New(log.DisabledLogger(), lifecycle.Options{})
+	td.CmpString(t, s.Register(m), "invalid admin listener address: ")
+
+	s.admin.Addr = "127.0.0.1:0"
+	td.CmpNoError(t, s.Register(m))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+
+	for range 100 {
+		if s.ready.Load() {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	td.Cmp(t, s.ready.Load(), true, "ready once the listeners are bound")
+
+	cancel()
+	td.CmpNoError(t, <-done)
+	td.Cmp(t, s.ready.Load(), false)
+}
+
+func newTestServer(t *testing.T, opts HTTPOptions) *Server {
+	t.Helper()
+
+	data, err := static.OpenAPI()
+	td.CmpNoError(t, err)
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	td.CmpNoError(t, err)
+
+	return NewServer(ServerOptions{
+		HTTPAddr: ":0",
+		GRPCAddr: ":0",
+		Logger:   log.DisabledLogger(),
+		OpenAPI:  doc,
+		Cat:      http.NotFoundHandler(),
+		CatRPC:   noopGRPCService{},
+		HTTP:     opts,
+	})
+}
+
+type noopGRPCService struct{}
+
+func (noopGRPCService) Register(grpc.ServiceRegistrar) {}
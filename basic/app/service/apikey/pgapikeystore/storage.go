@@ -0,0 +1,221 @@
+package pgapikeystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/apikey"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ apikey.Storage = (*Storage)(nil)
+)
+
+// Storage implements apikey.Storage interface using Postgres.
+type Storage struct{ conn *pgxpool.Pool }
+
+// New returns a pointer to a new instance of Storage struct.
+func New(conn *pgxpool.Pool) *Storage { return &Storage{conn: conn} }
+
+func (s *Storage) SaveKey(ctx context.Context, k *apikey.APIKey) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `INSERT INTO api_keys (id, name, tenant_id, key_hash, scopes, created_at, expires_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7);`
+
+	if _, err := tx.Exec(ctx, q, k.ID, k.Name, k.Tenant, k.Hash, k.Scopes, k.CreatedAt, k.ExpiresAt); err != nil {
+		return toServiceError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetKeyByID(ctx context.Context, id string) (m *apikey.APIKey, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadOnly,
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `SELECT id, name, COALESCE(tenant_id, ''), key_hash, scopes, created_at, expires_at, revoked_at, last_used_at
+		FROM api_keys WHERE id = $1 LIMIT 1;`
+
+	model, err := scanKey(tx.QueryRow(ctx, q, id))
+	if err != nil {
+		return nil, toServiceError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return model, nil
+}
+
+func (s *Storage) ListKeys(ctx context.Context) (m []*apikey.APIKey, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadOnly,
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `SELECT id, name, COALESCE(tenant_id, ''), key_hash, scopes, created_at, expires_at, revoked_at, last_used_at
+		FROM api_keys ORDER BY id;`
+
+	rows, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, toServiceError(err)
+	}
+	defer rows.Close()
+
+	var models []*apikey.APIKey
+	for rows.Next() {
+		model, err := scanKey(rows)
+		if err != nil {
+			return nil, toServiceError(err)
+		}
+
+		models = append(models, model)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, toServiceError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return models, nil
+}
+
+func (s *Storage) RevokeKey(ctx context.Context, id string, at time.Time) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1;`
+
+	tag, err := tx.Exec(ctx, q, id, at)
+	if err != nil {
+		return toServiceError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return xerr.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TouchKey(ctx context.Context, id string, at time.Time) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1;`
+
+	if _, err := tx.Exec(ctx, q, id, at); err != nil {
+		return toServiceError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func scanKey(row pgx.Row) (*apikey.APIKey, error) {
+	var model apikey.APIKey
+
+	err := row.Scan(
+		&model.ID, &model.Name, &model.Tenant, &model.Hash, &model.Scopes,
+		&model.CreatedAt, &model.ExpiresAt, &model.RevokedAt, &model.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+func toServiceError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return xerr.ErrNotFound
+	}
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.NoData, pgerrcode.NoDataFound:
+			return fmt.Errorf("postgres: %w: %s", xerr.ErrNotFound, pgErr.Detail)
+		case pgerrcode.UniqueViolation:
+			return fmt.Errorf("postgres: %w: %s", xerr.ErrAlreadyExists, pgErr.Detail)
+		default:
+			return fmt.Errorf("postgres: %w", pgErr)
+		}
+	}
+
+	return fmt.Errorf("postgres: %w", err)
+}
@@ -0,0 +1,259 @@
+// Package apikey holds logic of API keys, which authenticate
+// service-to-service callers without a token issuer.
+//
+// A key is shown once on creation, only its SHA-256 hash is stored.
+// Keys look like ak_<id>.<secret>, the id part is used to find the key.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idkit"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+const (
+	// Scheme is the scheme of the Authorization header the key can be sent with.
+	Scheme = "ApiKey"
+
+	// Header is the header the key can be sent in,
+	// besides the Authorization header with the Scheme.
+	Header = "X-API-Key"
+
+	// SubjectPrefix prefixes the key id in the subject of principals authenticated by keys.
+	SubjectPrefix = "apikey:"
+
+	// keyPrefix prefixes every key, so leaked keys are easy to detect.
+	keyPrefix = "ak_"
+
+	// secretSize represents size of the random part of a key in bytes.
+	secretSize = 32
+
+	// lastUsedResolution limits how often the last used time of a key is updated.
+	lastUsedResolution = time.Minute
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ Service        = (*ServiceImpl)(nil)
+	_ auth.Extractor = (*ServiceImpl)(nil)
+)
+
+// Service holds logic of work with APIKey entity.
+type Service interface {
+	// CreateKey creates an APIKey of the given tenant with the given scopes, which expires after ttl,
+	// zero ttl means the key never expires. A key without a tenant selects the tenant by the
+	// tenant.Header only if it is granted tenant.CrossTenantScope. Returns the key itself along
+	// with the created APIKey, the key can not be recovered later.
+	CreateKey(ctx context.Context, name, tenantID string, scopes []string, ttl time.Duration) (string, *APIKey, error)
+
+	// RevokeKey revokes the APIKey with the given id,
+	// returns ErrNotFound in case given id can not be found.
+	RevokeKey(ctx context.Context, id string) error
+
+	// ListKeys returns all APIKeys ordered by id, including revoked and expired ones.
+	ListKeys(ctx context.Context) ([]*APIKey, error)
+
+	// Authenticate returns the APIKey of the given key.
+	// Returns auth.ErrInvalidToken if the key is unknown or revoked
+	// and auth.ErrTokenExpired if the key is expired.
+	Authenticate(ctx context.Context, key string) (*APIKey, error)
+}
+
+// Storage represents layer of persistence for the APIKey entity.
+type Storage interface {
+	// SaveKey saves given APIKey record to the storage.
+	SaveKey(ctx context.Context, key *APIKey) error
+
+	// GetKeyByID tries to find an APIKey in the storage by given id.
+	// Returns ErrNotFound if APIKey with given id can not be found in the database.
+	GetKeyByID(ctx context.Context, id string) (*APIKey, error)
+
+	// ListKeys returns all APIKeys ordered by id.
+	ListKeys(ctx context.Context) ([]*APIKey, error)
+
+	// RevokeKey sets the revocation time of the APIKey with the given id if it is not revoked yet.
+	// Returns ErrNotFound if APIKey with given id can not be found in the database.
+	RevokeKey(ctx context.Context, id string, at time.Time) error
+
+	// TouchKey sets the last used time of the APIKey with the given id.
+	TouchKey(ctx context.Context, id string, at time.Time) error
+}
+
+// APIKey represents an APIKey entity in a context of implemented system.
+type APIKey struct {
+	ID         string
+	Name       string
+	Tenant     string
+	Hash       []byte
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+// ServiceImpl implements Service interface.
+type ServiceImpl struct {
+	storage Storage
+	now     func() time.Time
+}
+
+// NewService returns a pointer to a new instance of Service implementation.
+func NewService(storage Storage) *ServiceImpl {
+	s := ServiceImpl{
+		storage: storage,
+		now:     time.Now,
+	}
+
+	return &s
+}
+
+func (s *ServiceImpl) CreateKey(
+	ctx context.Context, name, tenantID string, scopes []string, ttl time.Duration,
+) (string, *APIKey, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("generate secret: %w", err)
+	}
+
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	now := s.now().UTC()
+
+	key := APIKey{
+		ID:        idkit.XID(),
+		Name:      name,
+		Tenant:    tenantID,
+		Hash:      hash(encodedSecret),
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.storage.SaveKey(ctx, &key); err != nil {
+		return "", nil, fmt.Errorf("save api key '%s' to the storage: %w", key.ID, err)
+	}
+
+	return keyPrefix + key.ID + "." + encodedSecret, &key, nil
+}
+
+func (s *ServiceImpl) RevokeKey(ctx context.Context, id string) error {
+	if err := s.storage.RevokeKey(ctx, id, s.now().UTC()); err != nil {
+		return fmt.Errorf("revoke api key '%s' in the storage: %w", id, err)
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) ListKeys(ctx context.Context) ([]*APIKey, error) {
+	keys, err := s.storage.ListKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys from the storage: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *ServiceImpl) Authenticate(ctx context.Context, key string) (*APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(key, keyPrefix), ".")
+	if !ok || !strings.HasPrefix(key, keyPrefix) || id == "" || secret == "" {
+		return nil, fmt.Errorf("%w: malformed api key", auth.ErrInvalidToken)
+	}
+
+	apiKey, err := s.storage.GetKeyByID(ctx, id)
+	if errors.Is(err, xerr.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key '%s'", auth.ErrInvalidToken, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get api key '%s' from the storage: %w", id, err)
+	}
+
+	if subtle.ConstantTimeCompare(apiKey.Hash, hash(secret)) != 1 {
+		return nil, fmt.Errorf("%w: api key '%s' secret mismatch", auth.ErrInvalidToken, id)
+	}
+
+	now := s.now().UTC()
+
+	if apiKey.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key '%s' is revoked", auth.ErrInvalidToken, id)
+	}
+
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, fmt.Errorf("%w: api key '%s' expired", auth.ErrTokenExpired, id)
+	}
+
+	// The last used time is coarse, so a key in active use does not cause a write per request.
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		if err := s.storage.TouchKey(ctx, id, now); err != nil {
+			return nil, fmt.Errorf("touch api key '%s' in the storage: %w", id, err)
+		}
+
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey, nil
+}
+
+// Extract returns the principal of the key from the Authorization header with
+// the ApiKey scheme or from the X-API-Key header. The subject of the principal
+// is the key id prefixed with SubjectPrefix, the tenant and the scopes are those of the key.
+func (s *ServiceImpl) Extract(ctx context.Context, header http.Header) (*auth.Principal, error) {
+	key := strings.TrimSpace(header.Get(Header))
+
+	if scheme, value, ok := strings.Cut(header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, Scheme) {
+		key = strings.TrimSpace(value)
+	}
+
+	if key == "" {
+		return nil, auth.ErrNoCredentials
+	}
+
+	apiKey, err := s.Authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := auth.Principal{
+		Subject:   SubjectPrefix + apiKey.ID,
+		Scopes:    apiKey.Scopes,
+		Tenant:    apiKey.Tenant,
+		ExpiresAt: derefTime(apiKey.ExpiresAt),
+	}
+
+	return &p, nil
+}
+
+func (*ServiceImpl) Schemes() []string {
+	return []string{Scheme}
+}
+
+// hash returns the hash of the secret part of a key. Secrets are random
+// and long, so a fast hash is enough, unlike for user passwords.
+func hash(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+
+	return sum[:]
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
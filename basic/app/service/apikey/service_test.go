@@ -0,0 +1,309 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestServiceImpl_Authenticate(t *testing.T) {
+	type tcase struct {
+		key     func(t *testing.T, s *ServiceImpl) string
+		wantErr error
+	}
+
+	create := func(ttl time.Duration) func(t *testing.T, s *ServiceImpl) string {
+		return func(t *testing.T, s *ServiceImpl) string {
+			key, _, err := s.CreateKey(context.Background(), "test", "", []string{"cat:read"}, ttl)
+			td.CmpNoError(t, err)
+
+			return key
+		}
+	}
+
+	tests := map[string]tcase{
+		"Valid": {
+			key: create(0),
+		},
+		"Valid before expiration": {
+			key: create(time.Hour),
+		},
+		"Expired": {
+			key: func(t *testing.T, s *ServiceImpl) string {
+				key := create(time.Minute)(t, s)
+				s.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+				return key
+			},
+			wantErr: auth.ErrTokenExpired,
+		},
+		"Revoked": {
+			key: func(t *testing.T, s *ServiceImpl) string {
+				key, apiKey, err := s.CreateKey(context.Background(), "test", "", nil, 0)
+				td.CmpNoError(t, err)
+				td.CmpNoError(t, s.RevokeKey(context.Background(), apiKey.ID))
+
+				return key
+			},
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Wrong secret": {
+			key: func(t *testing.T, s *ServiceImpl) string {
+				key := create(0)(t, s)
+
+				return key[:len(key)-1] + "x"
+			},
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Unknown id": {
+			key:     func(*testing.T, *ServiceImpl) string { return "ak_UNKNOWN.secret" },
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Malformed": {
+			key:     func(*testing.T, *ServiceImpl) string { return "not-a-key" },
+			wantErr: auth.ErrInvalidToken,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewService(newMemStorage())
+
+			got, err := s.Authenticate(context.Background(), tc.key(t, s))
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got.Scopes, []string{"cat:read"})
+			td.CmpNotNil(t, got.LastUsedAt)
+		})
+	}
+}
+
+func TestServiceImpl_Authenticate_lastUsed(t *testing.T) {
+	storage := newMemStorage()
+	s := NewService(storage)
+
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	key, apiKey, err := s.CreateKey(context.Background(), "test", "", nil, 0)
+	td.CmpNoError(t, err)
+	td.CmpNil(t, apiKey.LastUsedAt)
+
+	_, err = s.Authenticate(context.Background(), key)
+	td.CmpNoError(t, err)
+	td.Cmp(t, *storage.keys[apiKey.ID].LastUsedAt, now)
+
+	// Updates are coarse.
+	first := now
+	now = now.Add(lastUsedResolution / 2)
+
+	_, err = s.Authenticate(context.Background(), key)
+	td.CmpNoError(t, err)
+	td.Cmp(t, *storage.keys[apiKey.ID].LastUsedAt, first)
+
+	now = now.Add(lastUsedResolution)
+
+	_, err = s.Authenticate(context.Background(), key)
+	td.CmpNoError(t, err)
+	td.Cmp(t, *storage.keys[apiKey.ID].LastUsedAt, now)
+}
+
+func TestServiceImpl_Extract(t *testing.T) {
+	s := NewService(newMemStorage())
+
+	key, apiKey, err := s.CreateKey(context.Background(), "test", "", []string{"cat:read"}, 0)
+	td.CmpNoError(t, err)
+	td.Cmp(t, strings.HasPrefix(key, "ak_"+apiKey.ID+"."), true)
+
+	type tcase struct {
+		header http.Header
+
+		wantErr error
+	}
+
+	tests := map[string]tcase{
+		"Authorization header": {
+			header: http.Header{"Authorization": {"ApiKey " + key}},
+		},
+		"X-API-Key header": {
+			header: headerOf(Header, key),
+		},
+		"Bearer token": {
+			header:  http.Header{"Authorization": {"Bearer token"}},
+			wantErr: auth.ErrNoCredentials,
+		},
+		"Invalid key": {
+			header:  headerOf(Header, key+"x"),
+			wantErr: auth.ErrInvalidToken,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Extract(context.Background(), tc.header)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, &auth.Principal{Subject: SubjectPrefix + apiKey.ID, Scopes: []string{"cat:read"}})
+		})
+	}
+}
+
+func TestServiceImpl_Extract_tenant(t *testing.T) {
+	s := NewService(newMemStorage())
+
+	boundKey, _, err := s.CreateKey(context.Background(), "acme service", "acme", []string{"cat:read"}, 0)
+	td.CmpNoError(t, err)
+
+	unboundKey, _, err := s.CreateKey(context.Background(), "service", "", []string{"cat:read"}, 0)
+	td.CmpNoError(t, err)
+
+	crossTenantKey, _, err := s.CreateKey(context.Background(), "admin", "", []string{tenant.CrossTenantScope}, 0)
+	td.CmpNoError(t, err)
+
+	handler := middlewares.AuthMiddleware(log.DisabledLogger(), s)(
+		middlewares.TenantMiddleware(tenant.NewResolver(""))(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id, _ := tenant.FromContext(r.Context())
+				w.Write([]byte(id)) //nolint: errcheck
+			}),
+		),
+	)
+
+	type tcase struct {
+		key    string
+		tenant string
+
+		wantStatus int
+		wantBody   string
+	}
+
+	tests := map[string]tcase{
+		"Bound key": {
+			key:        boundKey,
+			wantStatus: http.StatusOK,
+			wantBody:   "acme",
+		},
+		"Bound key of foreign tenant": {
+			key:        boundKey,
+			tenant:     "globex",
+			wantStatus: http.StatusForbidden,
+		},
+		"Unbound key of foreign tenant": {
+			key:        unboundKey,
+			tenant:     "globex",
+			wantStatus: http.StatusForbidden,
+		},
+		"Cross-tenant key": {
+			key:        crossTenantKey,
+			tenant:     "globex",
+			wantStatus: http.StatusOK,
+			wantBody:   "globex",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cat/test-id", http.NoBody)
+			req.Header.Set(Header, tc.key)
+
+			if tc.tenant != "" {
+				req.Header.Set(tenant.Header, tc.tenant)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			td.Cmp(t, rec.Code, tc.wantStatus)
+
+			if tc.wantStatus == http.StatusOK {
+				td.Cmp(t, rec.Body.String(), tc.wantBody)
+			}
+		})
+	}
+}
+
+func headerOf(name, value string) http.Header {
+	h := http.Header{}
+	h.Set(name, value)
+
+	return h
+}
+
+// memStorage implements Storage in memory.
+type memStorage struct {
+	mu   sync.Mutex
+	keys map[string]APIKey
+}
+
+func newMemStorage() *memStorage { return &memStorage{keys: make(map[string]APIKey)} }
+
+func (s *memStorage) SaveKey(_ context.Context, k *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[k.ID] = *k
+
+	return nil
+}
+
+func (s *memStorage) GetKeyByID(_ context.Context, id string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, xerr.ErrNotFound
+	}
+
+	return &k, nil
+}
+
+func (s *memStorage) ListKeys(context.Context) ([]*APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memStorage) RevokeKey(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return xerr.ErrNotFound
+	}
+
+	k.RevokedAt = &at
+	s.keys[id] = k
+
+	return nil
+}
+
+func (s *memStorage) TouchKey(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.keys[id]
+	k.LastUsedAt = &at
+	s.keys[id] = k
+
+	return nil
+}
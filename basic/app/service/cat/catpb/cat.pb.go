@@ -0,0 +1,541 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: cat.proto
+
+package catpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Cat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Breed         string                 `protobuf:"bytes,3,opt,name=breed,proto3" json:"breed,omitempty"`
+	Age           uint32                 `protobuf:"varint,4,opt,name=age,proto3" json:"age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cat) Reset() {
+	*x = Cat{}
+	mi := &file_cat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cat) ProtoMessage() {}
+
+func (x *Cat) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cat.ProtoReflect.Descriptor instead.
+func (*Cat) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Cat) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Cat) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Cat) GetBreed() string {
+	if x != nil {
+		return x.Breed
+	}
+	return ""
+}
+
+func (x *Cat) GetAge() uint32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+type GetCatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCatRequest) Reset() {
+	*x = GetCatRequest{}
+	mi := &file_cat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCatRequest) ProtoMessage() {}
+
+func (x *GetCatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCatRequest.ProtoReflect.Descriptor instead.
+func (*GetCatRequest) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCatRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListCatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         uint32                 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        uint32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatsRequest) Reset() {
+	*x = ListCatsRequest{}
+	mi := &file_cat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatsRequest) ProtoMessage() {}
+
+func (x *ListCatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatsRequest.ProtoReflect.Descriptor instead.
+func (*ListCatsRequest) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListCatsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListCatsRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListCatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cats          []*Cat                 `protobuf:"bytes,1,rep,name=cats,proto3" json:"cats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCatsResponse) Reset() {
+	*x = ListCatsResponse{}
+	mi := &file_cat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCatsResponse) ProtoMessage() {}
+
+func (x *ListCatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCatsResponse.ProtoReflect.Descriptor instead.
+func (*ListCatsResponse) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListCatsResponse) GetCats() []*Cat {
+	if x != nil {
+		return x.Cats
+	}
+	return nil
+}
+
+type CreateCatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Breed         string                 `protobuf:"bytes,2,opt,name=breed,proto3" json:"breed,omitempty"`
+	Age           uint32                 `protobuf:"varint,3,opt,name=age,proto3" json:"age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCatRequest) Reset() {
+	*x = CreateCatRequest{}
+	mi := &file_cat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCatRequest) ProtoMessage() {}
+
+func (x *CreateCatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCatRequest.ProtoReflect.Descriptor instead.
+func (*CreateCatRequest) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateCatRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCatRequest) GetBreed() string {
+	if x != nil {
+		return x.Breed
+	}
+	return ""
+}
+
+func (x *CreateCatRequest) GetAge() uint32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+type UpdateCatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Breed         string                 `protobuf:"bytes,3,opt,name=breed,proto3" json:"breed,omitempty"`
+	Age           uint32                 `protobuf:"varint,4,opt,name=age,proto3" json:"age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCatRequest) Reset() {
+	*x = UpdateCatRequest{}
+	mi := &file_cat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCatRequest) ProtoMessage() {}
+
+func (x *UpdateCatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCatRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCatRequest) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateCatRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateCatRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateCatRequest) GetBreed() string {
+	if x != nil {
+		return x.Breed
+	}
+	return ""
+}
+
+func (x *UpdateCatRequest) GetAge() uint32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+type DeleteCatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCatRequest) Reset() {
+	*x = DeleteCatRequest{}
+	mi := &file_cat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCatRequest) ProtoMessage() {}
+
+func (x *DeleteCatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCatRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCatRequest) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteCatRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteCatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCatResponse) Reset() {
+	*x = DeleteCatResponse{}
+	mi := &file_cat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCatResponse) ProtoMessage() {}
+
+func (x *DeleteCatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCatResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCatResponse) Descriptor() ([]byte, []int) {
+	return file_cat_proto_rawDescGZIP(), []int{7}
+}
+
+var File_cat_proto protoreflect.FileDescriptor
+
+const file_cat_proto_rawDesc = "" +
+	"\n" +
+	"\tcat.proto\x12\x06cat.v1\"Q\n" +
+	"\x03Cat\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05breed\x18\x03 \x01(\tR\x05breed\x12\x10\n" +
+	"\x03age\x18\x04 \x01(\rR\x03age\"\x1f\n" +
+	"\rGetCatRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"?\n" +
+	"\x0fListCatsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\rR\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\rR\x06offset\"3\n" +
+	"\x10ListCatsResponse\x12\x1f\n" +
+	"\x04cats\x18\x01 \x03(\v2\v.cat.v1.CatR\x04cats\"N\n" +
+	"\x10CreateCatRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05breed\x18\x02 \x01(\tR\x05breed\x12\x10\n" +
+	"\x03age\x18\x03 \x01(\rR\x03age\"^\n" +
+	"\x10UpdateCatRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05breed\x18\x03 \x01(\tR\x05breed\x12\x10\n" +
+	"\x03age\x18\x04 \x01(\rR\x03age\"\"\n" +
+	"\x10DeleteCatRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x13\n" +
+	"\x11DeleteCatResponse2\xa3\x02\n" +
+	"\n" +
+	"CatService\x12,\n" +
+	"\x06GetCat\x12\x15.cat.v1.GetCatRequest\x1a\v.cat.v1.Cat\x12=\n" +
+	"\bListCats\x12\x17.cat.v1.ListCatsRequest\x1a\x18.cat.v1.ListCatsResponse\x122\n" +
+	"\tCreateCat\x12\x18.cat.v1.CreateCatRequest\x1a\v.cat.v1.Cat\x122\n" +
+	"\tUpdateCat\x12\x18.cat.v1.UpdateCatRequest\x1a\v.cat.v1.Cat\x12@\n" +
+	"\tDeleteCat\x12\x18.cat.v1.DeleteCatRequest\x1a\x19.cat.v1.DeleteCatResponseBDZBgithub.com/KitRUM/golang-blueprint/basicrest/app/service/cat/catpbb\x06proto3"
+
+var (
+	file_cat_proto_rawDescOnce sync.Once
+	file_cat_proto_rawDescData []byte
+)
+
+func file_cat_proto_rawDescGZIP() []byte {
+	file_cat_proto_rawDescOnce.Do(func() {
+		file_cat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cat_proto_rawDesc), len(file_cat_proto_rawDesc)))
+	})
+	return file_cat_proto_rawDescData
+}
+
+var file_cat_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cat_proto_goTypes = []any{
+	(*Cat)(nil),               // 0: cat.v1.Cat
+	(*GetCatRequest)(nil),     // 1: cat.v1.GetCatRequest
+	(*ListCatsRequest)(nil),   // 2: cat.v1.ListCatsRequest
+	(*ListCatsResponse)(nil),  // 3: cat.v1.ListCatsResponse
+	(*CreateCatRequest)(nil),  // 4: cat.v1.CreateCatRequest
+	(*UpdateCatRequest)(nil),  // 5: cat.v1.UpdateCatRequest
+	(*DeleteCatRequest)(nil),  // 6: cat.v1.DeleteCatRequest
+	(*DeleteCatResponse)(nil), // 7: cat.v1.DeleteCatResponse
+}
+var file_cat_proto_depIdxs = []int32{
+	0, // 0: cat.v1.ListCatsResponse.cats:type_name -> cat.v1.Cat
+	1, // 1: cat.v1.CatService.GetCat:input_type -> cat.v1.GetCatRequest
+	2, // 2: cat.v1.CatService.ListCats:input_type -> cat.v1.ListCatsRequest
+	4, // 3: cat.v1.CatService.CreateCat:input_type -> cat.v1.CreateCatRequest
+	5, // 4: cat.v1.CatService.UpdateCat:input_type -> cat.v1.UpdateCatRequest
+	6, // 5: cat.v1.CatService.DeleteCat:input_type -> cat.v1.DeleteCatRequest
+	0, // 6: cat.v1.CatService.GetCat:output_type -> cat.v1.Cat
+	3, // 7: cat.v1.CatService.ListCats:output_type -> cat.v1.ListCatsResponse
+	0, // 8: cat.v1.CatService.CreateCat:output_type -> cat.v1.Cat
+	0, // 9: cat.v1.CatService.UpdateCat:output_type -> cat.v1.Cat
+	7, // 10: cat.v1.CatService.DeleteCat:output_type -> cat.v1.DeleteCatResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_cat_proto_init() }
+func file_cat_proto_init() {
+	if File_cat_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cat_proto_rawDesc), len(file_cat_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cat_proto_goTypes,
+		DependencyIndexes: file_cat_proto_depIdxs,
+		MessageInfos:      file_cat_proto_msgTypes,
+	}.Build()
+	File_cat_proto = out.File
+	file_cat_proto_goTypes = nil
+	file_cat_proto_depIdxs = nil
+}
@@ -0,0 +1,287 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             (unknown)
+// source: cat.proto
+
+package catpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CatService_GetCat_FullMethodName    = "/cat.v1.CatService/GetCat"
+	CatService_ListCats_FullMethodName  = "/cat.v1.CatService/ListCats"
+	CatService_CreateCat_FullMethodName = "/cat.v1.CatService/CreateCat"
+	CatService_UpdateCat_FullMethodName = "/cat.v1.CatService/UpdateCat"
+	CatService_DeleteCat_FullMethodName = "/cat.v1.CatService/DeleteCat"
+)
+
+// CatServiceClient is the client API for CatService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CatService exposes the Cat domain logic over gRPC.
+type CatServiceClient interface {
+	// GetCat returns a Cat by id.
+	GetCat(ctx context.Context, in *GetCatRequest, opts ...grpc.CallOption) (*Cat, error)
+	// ListCats returns a page of Cats ordered by id.
+	ListCats(ctx context.Context, in *ListCatsRequest, opts ...grpc.CallOption) (*ListCatsResponse, error)
+	// CreateCat creates a new Cat.
+	CreateCat(ctx context.Context, in *CreateCatRequest, opts ...grpc.CallOption) (*Cat, error)
+	// UpdateCat replaces name, breed and age of an existing Cat.
+	UpdateCat(ctx context.Context, in *UpdateCatRequest, opts ...grpc.CallOption) (*Cat, error)
+	// DeleteCat deletes a Cat by id.
+	DeleteCat(ctx context.Context, in *DeleteCatRequest, opts ...grpc.CallOption) (*DeleteCatResponse, error)
+}
+
+type catServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCatServiceClient(cc grpc.ClientConnInterface) CatServiceClient {
+	return &catServiceClient{cc}
+}
+
+func (c *catServiceClient) GetCat(ctx context.Context, in *GetCatRequest, opts ...grpc.CallOption) (*Cat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cat)
+	err := c.cc.Invoke(ctx, CatService_GetCat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catServiceClient) ListCats(ctx context.Context, in *ListCatsRequest, opts ...grpc.CallOption) (*ListCatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCatsResponse)
+	err := c.cc.Invoke(ctx, CatService_ListCats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catServiceClient) CreateCat(ctx context.Context, in *CreateCatRequest, opts ...grpc.CallOption) (*Cat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cat)
+	err := c.cc.Invoke(ctx, CatService_CreateCat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catServiceClient) UpdateCat(ctx context.Context, in *UpdateCatRequest, opts ...grpc.CallOption) (*Cat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Cat)
+	err := c.cc.Invoke(ctx, CatService_UpdateCat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catServiceClient) DeleteCat(ctx context.Context, in *DeleteCatRequest, opts ...grpc.CallOption) (*DeleteCatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCatResponse)
+	err := c.cc.Invoke(ctx, CatService_DeleteCat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CatServiceServer is the server API for CatService service.
+// All implementations must embed UnimplementedCatServiceServer
+// for forward compatibility.
+//
+// CatService exposes the Cat domain logic over gRPC.
+type CatServiceServer interface {
+	// GetCat returns a Cat by id.
+	GetCat(context.Context, *GetCatRequest) (*Cat, error)
+	// ListCats returns a page of Cats ordered by id.
+	ListCats(context.Context, *ListCatsRequest) (*ListCatsResponse, error)
+	// CreateCat creates a new Cat.
+	CreateCat(context.Context, *CreateCatRequest) (*Cat, error)
+	// UpdateCat replaces name, breed and age of an existing Cat.
+	UpdateCat(context.Context, *UpdateCatRequest) (*Cat, error)
+	// DeleteCat deletes a Cat by id.
+	DeleteCat(context.Context, *DeleteCatRequest) (*DeleteCatResponse, error)
+	mustEmbedUnimplementedCatServiceServer()
+}
+
+// UnimplementedCatServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCatServiceServer struct{}
+
+func (UnimplementedCatServiceServer) GetCat(context.Context, *GetCatRequest) (*Cat, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCat not implemented")
+}
+func (UnimplementedCatServiceServer) ListCats(context.Context, *ListCatsRequest) (*ListCatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCats not implemented")
+}
+func (UnimplementedCatServiceServer) CreateCat(context.Context, *CreateCatRequest) (*Cat, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateCat not implemented")
+}
+func (UnimplementedCatServiceServer) UpdateCat(context.Context, *UpdateCatRequest) (*Cat, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateCat not implemented")
+}
+func (UnimplementedCatServiceServer) DeleteCat(context.Context, *DeleteCatRequest) (*DeleteCatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteCat not implemented")
+}
+func (UnimplementedCatServiceServer) mustEmbedUnimplementedCatServiceServer() {}
+func (UnimplementedCatServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeCatServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CatServiceServer will
+// result in compilation errors.
+type UnsafeCatServiceServer interface {
+	mustEmbedUnimplementedCatServiceServer()
+}
+
+func RegisterCatServiceServer(s grpc.ServiceRegistrar, srv CatServiceServer) {
+	// If the following call panics, it indicates UnimplementedCatServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CatService_ServiceDesc, srv)
+}
+
+func _CatService_GetCat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).GetCat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_GetCat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).GetCat(ctx, req.(*GetCatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatService_ListCats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).ListCats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_ListCats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).ListCats(ctx, req.(*ListCatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatService_CreateCat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).CreateCat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_CreateCat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).CreateCat(ctx, req.(*CreateCatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatService_UpdateCat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).UpdateCat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_UpdateCat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).UpdateCat(ctx, req.(*UpdateCatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatService_DeleteCat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatServiceServer).DeleteCat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CatService_DeleteCat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatServiceServer).DeleteCat(ctx, req.(*DeleteCatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CatService_ServiceDesc is the grpc.ServiceDesc for CatService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CatService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cat.v1.CatService",
+	HandlerType: (*CatServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCat",
+			Handler:    _CatService_GetCat_Handler,
+		},
+		{
+			MethodName: "ListCats",
+			Handler:    _CatService_ListCats_Handler,
+		},
+		{
+			MethodName: "CreateCat",
+			Handler:    _CatService_CreateCat_Handler,
+		},
+		{
+			MethodName: "UpdateCat",
+			Handler:    _CatService_UpdateCat_Handler,
+		},
+		{
+			MethodName: "DeleteCat",
+			Handler:    _CatService_DeleteCat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cat.proto",
+}
@@ -0,0 +1,5 @@
+// Package catpb holds the protobuf definition of the Cat gRPC API
+// along with the Go code generated from it.
+package catpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cat.proto
@@ -0,0 +1,144 @@
+package cat
+
+import (
+	"context"
+	"errors"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat/catpb"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ catpb.CatServiceServer = (*GRPCTransport)(nil)
+)
+
+// GRPCTransport represents a gRPC transport for interaction with the Service logic.
+type GRPCTransport struct {
+	catpb.UnimplementedCatServiceServer
+
+	service Service
+}
+
+// NewGRPCTransport returns a pointer to a new instance of GRPCTransport.
+// Failures are logged by the call scoped logger, see log.FromContext.
+func NewGRPCTransport(service Service) *GRPCTransport {
+	t := GRPCTransport{
+		service: service,
+	}
+
+	return &t
+}
+
+// Register registers the transport as a CatService implementation on the given gRPC server.
+func (t *GRPCTransport) Register(s grpc.ServiceRegistrar) {
+	catpb.RegisterCatServiceServer(s, t)
+}
+
+func (t *GRPCTransport) GetCat(ctx context.Context, req *catpb.GetCatRequest) (*catpb.Cat, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	cat, err := t.service.GetCatByID(ctx, req.GetId())
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to get cat with '%s' id: %s", req.GetId(), err.Error())
+
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCat(cat), nil
+}
+
+func (t *GRPCTransport) ListCats(ctx context.Context, req *catpb.ListCatsRequest) (*catpb.ListCatsResponse, error) {
+	cats, err := t.service.ListCats(ctx, req.GetLimit(), req.GetOffset())
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to list cats: %s", err.Error())
+
+		return nil, toStatusError(err)
+	}
+
+	resp := catpb.ListCatsResponse{
+		Cats: make([]*catpb.Cat, 0, len(cats)),
+	}
+
+	for _, cat := range cats {
+		resp.Cats = append(resp.Cats, toProtoCat(cat))
+	}
+
+	return &resp, nil
+}
+
+func (t *GRPCTransport) CreateCat(ctx context.Context, req *catpb.CreateCatRequest) (*catpb.Cat, error) {
+	cat, err := t.service.CreateCat(ctx, req.GetName(), req.GetBreed(), req.GetAge())
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to create cat: %s", err.Error())
+
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCat(cat), nil
+}
+
+func (t *GRPCTransport) UpdateCat(ctx context.Context, req *catpb.UpdateCatRequest) (*catpb.Cat, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	cat, err := t.service.UpdateCat(ctx, req.GetId(), req.GetName(), req.GetBreed(), req.GetAge())
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to update cat with '%s' id: %s", req.GetId(), err.Error())
+
+		return nil, toStatusError(err)
+	}
+
+	return toProtoCat(cat), nil
+}
+
+func (t *GRPCTransport) DeleteCat(ctx context.Context, req *catpb.DeleteCatRequest) (*catpb.DeleteCatResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := t.service.DeleteCat(ctx, req.GetId()); err != nil {
+		log.FromContext(ctx).Errorf("Failed to delete cat with '%s' id: %s", req.GetId(), err.Error())
+
+		return nil, toStatusError(err)
+	}
+
+	return &catpb.DeleteCatResponse{}, nil
+}
+
+func toProtoCat(c *Cat) *catpb.Cat {
+	return &catpb.Cat{
+		Id:    c.ID,
+		Name:  c.Name,
+		Breed: c.Breed,
+		Age:   c.Age,
+	}
+}
+
+// toStatusError maps xerr errors to gRPC status errors.
+// Unknown errors are hidden behind codes.Internal.
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, xerr.ErrNotFound):
+		return status.Error(codes.NotFound, xerr.ErrNotFound.Error())
+	case errors.Is(err, xerr.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, xerr.ErrAlreadyExists.Error())
+	case errors.Is(err, xerr.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, xerr.ErrInvalidArgument.Error())
+	case errors.Is(err, xerr.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, xerr.ErrPermissionDenied.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
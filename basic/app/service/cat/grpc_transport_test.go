@@ -0,0 +1,188 @@
+package cat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat/catpb"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/maxatome/go-testdeep/td"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCTransport_GetCat(t *testing.T) {
+	type tcase struct {
+		service Service
+		id      string
+
+		wantCode codes.Code
+		wantCat  *catpb.Cat
+	}
+
+	tests := map[string]tcase{
+		"OK": {
+			service: &mockService{
+				getCatByIDFunc: func(ctx context.Context, id string) (*Cat, error) {
+					return &Cat{ID: id, Name: "test", Breed: "test-breed", Age: 10}, nil
+				},
+			},
+			id:       "test-id",
+			wantCode: codes.OK,
+			wantCat:  &catpb.Cat{Id: "test-id", Name: "test", Breed: "test-breed", Age: 10},
+		},
+		"NotFound": {
+			service: &mockService{
+				getCatByIDFunc: func(ctx context.Context, id string) (*Cat, error) {
+					return nil, xerr.ErrNotFound
+				},
+			},
+			id:       "test-id",
+			wantCode: codes.NotFound,
+		},
+		"PermissionDenied": {
+			service: &mockService{
+				getCatByIDFunc: func(ctx context.Context, id string) (*Cat, error) {
+					return nil, xerr.ErrPermissionDenied
+				},
+			},
+			id:       "test-id",
+			wantCode: codes.PermissionDenied,
+		},
+		"InvalidArgument": {
+			service:  &mockService{},
+			id:       "",
+			wantCode: codes.InvalidArgument,
+		},
+		"Internal": {
+			service: &mockService{
+				getCatByIDFunc: func(ctx context.Context, id string) (*Cat, error) {
+					return nil, errors.New("connection refused")
+				},
+			},
+			id:       "test-id",
+			wantCode: codes.Internal,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestGRPCClient(t, tc.service)
+
+			got, err := client.GetCat(context.Background(), &catpb.GetCatRequest{Id: tc.id})
+			td.Cmp(t, status.Code(err), tc.wantCode)
+
+			if tc.wantCat != nil {
+				td.Cmp(t, got.GetId(), tc.wantCat.GetId())
+				td.Cmp(t, got.GetName(), tc.wantCat.GetName())
+				td.Cmp(t, got.GetBreed(), tc.wantCat.GetBreed())
+				td.Cmp(t, got.GetAge(), tc.wantCat.GetAge())
+			}
+		})
+	}
+}
+
+func TestGRPCTransport_CreateCat(t *testing.T) {
+	type tcase struct {
+		service Service
+		req     *catpb.CreateCatRequest
+
+		wantCode codes.Code
+	}
+
+	tests := map[string]tcase{
+		"OK": {
+			service: &mockService{
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return &Cat{ID: "test-id", Name: name, Breed: breed, Age: age}, nil
+				},
+			},
+			req:      &catpb.CreateCatRequest{Name: "test", Breed: "test-breed", Age: 10},
+			wantCode: codes.OK,
+		},
+		"AlreadyExists": {
+			service: &mockService{
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return nil, xerr.ErrAlreadyExists
+				},
+			},
+			req:      &catpb.CreateCatRequest{Name: "test", Breed: "test-breed", Age: 10},
+			wantCode: codes.AlreadyExists,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestGRPCClient(t, tc.service)
+
+			_, err := client.CreateCat(context.Background(), tc.req)
+			td.Cmp(t, status.Code(err), tc.wantCode)
+		})
+	}
+}
+
+func TestGRPCTransport_DeleteCat(t *testing.T) {
+	type tcase struct {
+		service Service
+		id      string
+
+		wantCode codes.Code
+	}
+
+	tests := map[string]tcase{
+		"OK": {
+			service: &mockService{
+				deleteCatFunc: func(ctx context.Context, id string) error { return nil },
+			},
+			id:       "test-id",
+			wantCode: codes.OK,
+		},
+		"NotFound": {
+			service: &mockService{
+				deleteCatFunc: func(ctx context.Context, id string) error { return xerr.ErrNotFound },
+			},
+			id:       "test-id",
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestGRPCClient(t, tc.service)
+
+			_, err := client.DeleteCat(context.Background(), &catpb.DeleteCatRequest{Id: tc.id})
+			td.Cmp(t, status.Code(err), tc.wantCode)
+		})
+	}
+}
+
+func newTestGRPCClient(t *testing.T, service Service) catpb.CatServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+
+	NewGRPCTransport(service).Register(server)
+
+	go server.Serve(listener) //nolint: errcheck
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	td.CmpNoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return catpb.NewCatServiceClient(conn)
+}
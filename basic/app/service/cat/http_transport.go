@@ -1,16 +1,27 @@
 package cat
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
 
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
 	"github.com/go-chi/chi/v5"
 	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
 	"github.com/graphql-go/handler"
 )
 
@@ -26,22 +37,42 @@ var (
 	})
 )
 
+// FlagCreateResponse is the feature flag which makes the cat creation respond
+// with the created cat and its Location, the response has no body otherwise.
+const FlagCreateResponse = "cat.create-response"
+
 // Transport represents an HTTP transport for interaction with the Service logic.
 type Transport struct {
 	router chi.Router
-	log    log.Logger
 
 	service Service
+
+	// graphQL is set while the GraphQL endpoint is served, see SetGraphQL.
+	graphQL atomic.Bool
+}
+
+// TransportOption configures Transport.
+type TransportOption func(t *Transport)
+
+// WithoutGraphQL disables the GraphQL endpoint and GraphiQL, see Transport.SetGraphQL.
+func WithoutGraphQL() TransportOption {
+	return func(t *Transport) { t.graphQL.Store(false) }
 }
 
 // NewTransport returns a pointer to a new instance of Transport.
-func NewTransport(service Service, logger log.Logger) (*Transport, error) {
+// Failures are logged by the request scoped logger, see log.FromContext.
+func NewTransport(service Service, opts ...TransportOption) (*Transport, error) {
 	t := Transport{
 		router:  chi.NewRouter(),
-		log:     logger,
 		service: service,
 	}
 
+	t.graphQL.Store(true)
+
+	for _, opt := range opts {
+		opt(&t)
+	}
+
 	// Initialize routes.
 	t.router.Get("/{id}", t.catByID)
 	t.router.Post("/", t.createCat)
@@ -49,6 +80,7 @@ func NewTransport(service Service, logger log.Logger) (*Transport, error) {
 	// Initialize GraphQL schema.
 
 	gqlSchema, gqlSchemaErr := graphql.NewSchema(graphql.SchemaConfig{
+		Extensions: []graphql.Extension{gqlRequestIDExtension{}, gqlMetricsExtension{}},
 		Query: graphql.NewObject(graphql.ObjectConfig{
 			Name: "Query",
 			Fields: graphql.Fields{
@@ -60,7 +92,7 @@ func NewTransport(service Service, logger log.Logger) (*Transport, error) {
 							Type: graphql.NewNonNull(graphql.String),
 						},
 					},
-					Resolve: t.gqlGetCat,
+					Resolve: gqlTraced(t.gqlGetCat),
 				},
 			},
 		}),
@@ -81,7 +113,7 @@ func NewTransport(service Service, logger log.Logger) (*Transport, error) {
 							Type: graphql.NewNonNull(graphql.Int),
 						},
 					},
-					Resolve: t.gqlCreateCat,
+					Resolve: gqlTraced(t.gqlCreateCat),
 				},
 			},
 		}),
@@ -90,16 +122,39 @@ func NewTransport(service Service, logger log.Logger) (*Transport, error) {
 		return nil, fmt.Errorf("graphQL schema creation: %w", gqlSchemaErr)
 	}
 
-	// Mount the GraphQL handler.
-	t.router.Mount("/graphql", handler.New(&handler.Config{
+	// Register the GraphQL handler, GET serves GraphiQL and queries, POST serves queries and mutations.
+	gqlHandler := handler.New(&handler.Config{
 		Schema:   &gqlSchema,
 		Pretty:   true,
 		GraphiQL: true,
-	}))
+	})
+
+	gql := t.router.With(t.graphQLEnabled)
+	gql.Get("/graphql", gqlHandler.ServeHTTP)
+	gql.Post("/graphql", gqlHandler.ServeHTTP)
 
 	return &t, nil
 }
 
+// SetGraphQL enables or disables the GraphQL endpoint and GraphiQL while the transport serves requests.
+func (t *Transport) SetGraphQL(enabled bool) {
+	t.graphQL.Store(enabled)
+}
+
+// graphQLEnabled responds with 404 to the GraphQL requests while GraphQL is disabled.
+func (t *Transport) graphQLEnabled(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !t.graphQL.Load() {
+			http.NotFound(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
 func (t *Transport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	t.router.ServeHTTP(w, r)
 }
@@ -107,43 +162,38 @@ func (t *Transport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (t *Transport) catByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		t.log.Errorf("Query parameter id not found")
+		log.FromContext(r.Context()).Errorf("Query parameter id not found")
 
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "the cat id is missing")
 		return
 	}
 
-	type response struct {
-		ID    string `json:"id"`
-		Name  string `json:"name"`
-		Breed string `json:"breed"`
-		Age   int    `json:"age"`
-	}
-
 	cat, err := t.service.GetCatByID(r.Context(), id)
 	if err != nil {
-		t.log.Errorf("Failed to get cat with '%s' id: %s", id, err.Error())
+		log.FromContext(r.Context()).Errorf("Failed to get cat with '%s' id: %s", id, err.Error())
 
 		if errors.Is(err, xerr.ErrNotFound) {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			writeProblem(w, r, http.StatusNotFound, "the cat is not found")
 			return
 		}
 
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		if errors.Is(err, xerr.ErrPermissionDenied) {
+			writeProblem(w, r, http.StatusForbidden, "")
+			return
+		}
+
+		writeProblem(w, r, http.StatusInternalServerError, "")
 		return
 	}
 
-	resp := response{
-		ID:    cat.ID,
-		Name:  cat.Name,
-		Breed: cat.Breed,
-		Age:   int(cat.Age),
-	}
+	resp := newCatResponse(cat)
+
+	w.Header().Set("Content-Type", "application/json")
 
 	if err := json.NewEncoder(w).Encode(&resp); err != nil {
-		t.log.Errorf("failed encode %+v to json: %s", resp, err.Error())
+		log.FromContext(r.Context()).Errorf("failed encode %+v to json: %s", resp, err.Error())
 
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, "")
 		return
 	}
 }
@@ -160,26 +210,67 @@ func (t *Transport) createCat(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		body, _ := io.ReadAll(r.Body) //nolint: errcheck
 
-		t.log.Errorf("failed to decode request body %s: %s", string(body), err.Error())
+		log.FromContext(r.Context()).Errorf("failed to decode request body %s: %s", string(body), err.Error())
 
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "the request body is not a valid cat")
 		return
 	}
 	defer r.Body.Close()
 
-	if err := t.service.CreateCat(r.Context(), req.Name, req.Breed, req.Age); err != nil {
-		t.log.Errorf("failed to create cat: %s", err.Error())
+	cat, err := t.service.CreateCat(r.Context(), req.Name, req.Breed, req.Age)
+	if err != nil {
+		log.FromContext(r.Context()).Errorf("failed to create cat: %s", err.Error())
 
 		if errors.Is(err, xerr.ErrAlreadyExists) {
-			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+			writeProblem(w, r, http.StatusConflict, "the cat already exists")
 			return
 		}
 
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		if errors.Is(err, xerr.ErrPermissionDenied) {
+			writeProblem(w, r, http.StatusForbidden, "")
+			return
+		}
+
+		writeProblem(w, r, http.StatusInternalServerError, "")
+		return
+	}
+
+	if !flags.Enabled(r.Context(), FlagCreateResponse) {
+		w.WriteHeader(http.StatusCreated)
 		return
 	}
 
+	resp := newCatResponse(cat)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", path.Join(r.URL.Path, cat.ID))
 	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		log.FromContext(r.Context()).Errorf("failed encode %+v to json: %s", resp, err.Error())
+	}
+}
+
+// catResponse represents a cat in HTTP responses.
+type catResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Breed string `json:"breed"`
+	Age   int    `json:"age"`
+}
+
+func newCatResponse(cat *Cat) catResponse {
+	return catResponse{
+		ID:    cat.ID,
+		Name:  cat.Name,
+		Breed: cat.Breed,
+		Age:   int(cat.Age),
+	}
+}
+
+// writeProblem writes problem details response about the request, which hold the request ID.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problem.WriteRequest(w, r, status, detail) //nolint: errcheck
 }
 
 func (t *Transport) gqlGetCat(params graphql.ResolveParams) (any, error) {
@@ -212,9 +303,95 @@ func (t *Transport) gqlCreateCat(params graphql.ResolveParams) (any, error) {
 		return nil, fmt.Errorf("age must be a string")
 	}
 
-	if err := t.service.CreateCat(params.Context, name, breed, uint32(age)); err != nil {
+	if _, err := t.service.CreateCat(params.Context, name, breed, uint32(age)); err != nil {
 		return nil, fmt.Errorf("create cat: %w", err)
 	}
 
 	return true, nil
 }
+
+// gqlRequestIDExtension adds the request ID to the extensions of GraphQL responses,
+// since errors of GraphQL are sent with 200 status code and without problem details.
+type gqlRequestIDExtension struct{}
+
+func (gqlRequestIDExtension) Init(ctx context.Context, _ *graphql.Params) context.Context { return ctx }
+
+func (gqlRequestIDExtension) Name() string { return "request_id" }
+
+func (gqlRequestIDExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+func (gqlRequestIDExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (gqlRequestIDExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(*graphql.Result) {}
+}
+
+func (gqlRequestIDExtension) ResolveFieldDidStart(
+	ctx context.Context, _ *graphql.ResolveInfo,
+) (context.Context, graphql.ResolveFieldFinishFunc) {
+	return ctx, func(any, error) {}
+}
+
+func (gqlRequestIDExtension) HasResult() bool { return true }
+
+func (gqlRequestIDExtension) GetResult(ctx context.Context) any {
+	id, _ := requestid.FromContext(ctx)
+
+	return id
+}
+
+type gqlOperationKey struct{}
+
+// gqlOperation holds the root fields of a GraphQL request, e.g. "query getCat".
+type gqlOperation struct {
+	typ    string
+	fields []string
+}
+
+// gqlMetricsExtension labels metrics of GraphQL requests by the operation type and
+// its root fields instead of the single route, see metrics.SetOperation.
+// The operation names are chosen by clients, so they are not used as labels.
+type gqlMetricsExtension struct{}
+
+func (gqlMetricsExtension) Init(ctx context.Context, _ *graphql.Params) context.Context {
+	return context.WithValue(ctx, gqlOperationKey{}, &gqlOperation{})
+}
+
+func (gqlMetricsExtension) Name() string { return "metrics" }
+
+func (gqlMetricsExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+func (gqlMetricsExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (gqlMetricsExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(*graphql.Result) {}
+}
+
+func (gqlMetricsExtension) ResolveFieldDidStart(
+	ctx context.Context, info *graphql.ResolveInfo,
+) (context.Context, graphql.ResolveFieldFinishFunc) {
+	op, ok := ctx.Value(gqlOperationKey{}).(*gqlOperation)
+	if ok && info.Path != nil && info.Path.Prev == nil && !slices.Contains(op.fields, info.FieldName) {
+		if def, ok := info.Operation.(*ast.OperationDefinition); ok {
+			op.typ = def.Operation
+		}
+
+		op.fields = append(op.fields, info.FieldName)
+
+		metrics.SetOperation(ctx, op.typ+" "+strings.Join(op.fields, ","))
+	}
+
+	return ctx, func(any, error) {}
+}
+
+func (gqlMetricsExtension) HasResult() bool { return false }
+
+func (gqlMetricsExtension) GetResult(context.Context) any { return nil }
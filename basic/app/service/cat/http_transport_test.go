@@ -7,10 +7,22 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/static"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
 	"github.com/maxatome/go-testdeep/td"
 )
 
@@ -26,8 +38,8 @@ func TestTransport_createCat(t *testing.T) {
 	tests := map[string]tcase{
 		"201 Created": {
 			service: &mockService{
-				createCatFunc: func(ctx context.Context, name, breed string, age uint32) error {
-					return nil
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return &Cat{ID: "test-id", Name: name, Breed: breed, Age: age}, nil
 				},
 			},
 			payload: func() []byte {
@@ -49,8 +61,8 @@ func TestTransport_createCat(t *testing.T) {
 		},
 		"409 Conflict": {
 			service: &mockService{
-				createCatFunc: func(ctx context.Context, name, breed string, age uint32) error {
-					return xerr.ErrAlreadyExists
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return nil, xerr.ErrAlreadyExists
 				},
 			},
 			payload: func() []byte {
@@ -67,23 +79,33 @@ func TestTransport_createCat(t *testing.T) {
 				return b
 			}(),
 			wantStatus: http.StatusConflict,
-			wantBody:   []byte("Conflict\n"),
+			wantBody:   []byte(`{"title":"Conflict","status":409,"detail":"the cat already exists","instance":"/"}` + "\n"),
+		},
+		"403 Forbidden": {
+			service: &mockService{
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return nil, xerr.ErrPermissionDenied
+				},
+			},
+			payload:    []byte(`{"name":"test"}`),
+			wantStatus: http.StatusForbidden,
+			wantBody:   []byte(`{"title":"Forbidden","status":403,"instance":"/"}` + "\n"),
 		},
 		"400 Bad Request": {
 			service: &mockService{
-				createCatFunc: func(ctx context.Context, name, breed string, age uint32) error {
-					return nil
+				createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+					return &Cat{ID: "test-id", Name: name, Breed: breed, Age: age}, nil
 				},
 			},
 			payload:    func() []byte { return []byte(`{`) }(),
 			wantStatus: http.StatusBadRequest,
-			wantBody:   []byte("Bad Request\n"),
+			wantBody:   []byte(`{"title":"Bad Request","status":400,"detail":"the request body is not a valid cat","instance":"/"}` + "\n"),
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			handler, err := NewTransport(tc.service, log.DisabledLogger())
+			handler, err := NewTransport(tc.service)
 			td.CmpNoError(t, err)
 
 			server := httptest.NewServer(handler)
@@ -105,15 +127,212 @@ func TestTransport_createCat(t *testing.T) {
 	}
 }
 
+func TestTransport_createCatResponseFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	td.CmpNoError(t, os.WriteFile(path, []byte(FlagCreateResponse+":\n  principals: [user-1]\n"), 0o600))
+
+	evaluator := flags.NewEvaluator(flags.NewFileProvider(path))
+	_, err := evaluator.Reload(context.Background())
+	td.CmpNoError(t, err)
+
+	transport, err := NewTransport(&mockService{
+		createCatFunc: func(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+			return &Cat{ID: "test-id", Name: name, Breed: breed, Age: age}, nil
+		},
+	})
+	td.CmpNoError(t, err)
+
+	// The flags are evaluated for the principal found by the authentication.
+	handler := middlewares.AuthMiddleware(log.DisabledLogger(), auth.NewHeaderExtractor())(
+		middlewares.FlagsMiddleware(evaluator)(transport),
+	)
+
+	do := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Tom","breed":"Persian","age":3}`))
+		req.Header.Set(auth.SubjectHeader, subject)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		rec := do("user-1")
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Header().Get("Content-Type"), "application/json")
+		td.Cmp(t, rec.Header().Get("Location"), "/test-id")
+		td.Cmp(t, rec.Body.String(), `{"id":"test-id","name":"Tom","breed":"Persian","age":3}`+"\n")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		rec := do("user-2")
+		td.Cmp(t, rec.Code, http.StatusCreated)
+		td.Cmp(t, rec.Header().Get("Location"), "")
+		td.Cmp(t, rec.Body.String(), "")
+	})
+}
+
 type mockService struct {
 	getCatByIDFunc func(ctx context.Context, id string) (*Cat, error)
-	createCatFunc  func(ctx context.Context, name, breed string, age uint32) error
+	listCatsFunc   func(ctx context.Context, limit, offset uint32) ([]*Cat, error)
+	createCatFunc  func(ctx context.Context, name, breed string, age uint32) (*Cat, error)
+	updateCatFunc  func(ctx context.Context, id, name, breed string, age uint32) (*Cat, error)
+	deleteCatFunc  func(ctx context.Context, id string) error
 }
 
 func (m *mockService) GetCatByID(ctx context.Context, id string) (*Cat, error) {
 	return m.getCatByIDFunc(ctx, id)
 }
 
-func (m *mockService) CreateCat(ctx context.Context, name, breed string, age uint32) error {
+func (m *mockService) ListCats(ctx context.Context, limit, offset uint32) ([]*Cat, error) {
+	return m.listCatsFunc(ctx, limit, offset)
+}
+
+func (m *mockService) CreateCat(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
 	return m.createCatFunc(ctx, name, breed, age)
 }
+
+func (m *mockService) UpdateCat(ctx context.Context, id, name, breed string, age uint32) (*Cat, error) {
+	return m.updateCatFunc(ctx, id, name, breed, age)
+}
+
+func (m *mockService) DeleteCat(ctx context.Context, id string) error {
+	return m.deleteCatFunc(ctx, id)
+}
+
+func TestTransport_RoutesInSpec(t *testing.T) {
+	data, err := static.OpenAPI()
+	td.CmpNoError(t, err)
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	td.CmpNoError(t, err)
+
+	transport, err := NewTransport(&mockService{})
+	td.CmpNoError(t, err)
+
+	// Transport is mounted under /cat, see app.NewServer.
+	err = chi.Walk(transport.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := strings.TrimSuffix("/cat"+route, "/")
+
+		item := doc.Paths.Value(path)
+		if item == nil {
+			t.Errorf("route %s %s is not described in the openapi document", method, path)
+			return nil
+		}
+
+		if item.GetOperation(method) == nil {
+			t.Errorf("operation %s %s is not described in the openapi document", method, path)
+		}
+
+		return nil
+	})
+	td.CmpNoError(t, err)
+}
+
+func TestTransport_problemRequestID(t *testing.T) {
+	transport, err := NewTransport(&mockService{
+		getCatByIDFunc: func(context.Context, string) (*Cat, error) { return nil, xerr.ErrNotFound },
+	})
+	td.CmpNoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/1", http.NoBody)
+	req = req.WithContext(requestid.ContextWithID(req.Context(), "req-1"))
+
+	rec := httptest.NewRecorder()
+	transport.ServeHTTP(rec, req)
+
+	td.Cmp(t, rec.Code, http.StatusNotFound)
+	td.Cmp(t, rec.Header().Get("Content-Type"), problem.ContentType)
+
+	var body map[string]any
+	td.CmpNoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	td.CmpJSON(t, body, `{
+		"title": "Not Found",
+		"status": 404,
+		"detail": "the cat is not found",
+		"instance": "/1",
+		"request_id": "req-1"
+	}`, nil)
+}
+
+func TestTransport_graphQLRequestID(t *testing.T) {
+	transport, err := NewTransport(&mockService{
+		getCatByIDFunc: func(context.Context, string) (*Cat, error) { return nil, xerr.ErrNotFound },
+	})
+	td.CmpNoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{getCat(id:\"1\"){name}}"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(requestid.ContextWithID(req.Context(), "req-1"))
+
+	rec := httptest.NewRecorder()
+	transport.ServeHTTP(rec, req)
+
+	td.Cmp(t, rec.Code, http.StatusOK)
+
+	var body map[string]any
+	td.CmpNoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	td.CmpJSON(t, body, `{
+		"data": {"getCat": null},
+		"errors": NotEmpty(),
+		"extensions": {"request_id": "req-1"},
+	}`, nil)
+}
+
+func TestTransport_withoutGraphQL(t *testing.T) {
+	transport, err := NewTransport(&mockService{
+		getCatByIDFunc: func(_ context.Context, id string) (*Cat, error) { return &Cat{ID: id}, nil },
+	}, WithoutGraphQL())
+	td.CmpNoError(t, err)
+
+	query := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{getCat(id:\"1\"){name}}"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		transport.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	td.Cmp(t, query(), http.StatusNotFound)
+
+	transport.SetGraphQL(true)
+	td.Cmp(t, query(), http.StatusOK)
+
+	transport.SetGraphQL(false)
+	td.Cmp(t, query(), http.StatusNotFound)
+}
+
+func TestTransport_graphQLOperation(t *testing.T) {
+	transport, err := NewTransport(&mockService{
+		getCatByIDFunc: func(_ context.Context, id string) (*Cat, error) { return &Cat{ID: id}, nil },
+		createCatFunc: func(_ context.Context, name, breed string, age uint32) (*Cat, error) {
+			return &Cat{Name: name, Breed: breed, Age: age}, nil
+		},
+	})
+	td.CmpNoError(t, err)
+
+	tests := map[string]string{
+		`{getCat(id:"1"){name}}`: "query getCat",
+		`query Named {a: getCat(id:"1"){name} b: getCat(id:"2"){name}}`: "query getCat",
+		`mutation {createCat(name:"a", breed:"b", age:1)}`:              "mutation createCat",
+		`{unknownField}`: "",
+	}
+
+	for query, want := range tests {
+		t.Run(query, func(t *testing.T) {
+			payload, err := json.Marshal(map[string]string{"query": query})
+			td.CmpNoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(metrics.ContextWithOperation(req.Context()))
+
+			transport.ServeHTTP(httptest.NewRecorder(), req)
+
+			td.Cmp(t, metrics.Operation(req.Context()), want)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -14,10 +15,31 @@ import (
 )
 
 // Storage implements service.Storage interface using Postgres.
-type Storage struct{ conn *pgxpool.Pool }
+// Every query is scoped by the tenant from the context, see tenant.FromContext,
+// cats of other tenants are never visible.
+type Storage struct {
+	conn *pgxpool.Pool
+	rls  bool
+}
+
+// Option configures Storage.
+type Option func(s *Storage)
+
+// WithRowLevelSecurity makes Storage set the app.tenant_id setting for every
+// transaction, which the row level security policy of the cat table relies on.
+func WithRowLevelSecurity() Option {
+	return func(s *Storage) { s.rls = true }
+}
 
 // New returns a pointer to a new instance of Storage struct.
-func New(conn *pgxpool.Pool) *Storage { return &Storage{conn: conn} }
+func New(conn *pgxpool.Pool, opts ...Option) *Storage {
+	s := Storage{conn: conn}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
 
 func (s *Storage) SaveCat(ctx context.Context, c *cat.Cat) (tErr error) {
 	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
@@ -33,9 +55,15 @@ func (s *Storage) SaveCat(ctx context.Context, c *cat.Cat) (tErr error) {
 		}
 	}()
 
-	q := `INSERT INTO cat (id, name, breed, age) VALUES ($1, $2, $3, $4);`
+	tenantID, err := s.scope(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	q := `-- name: CreateCat
+	INSERT INTO cat (tenant_id, id, name, breed, age) VALUES ($1, $2, $3, $4, $5);`
 
-	if _, err := tx.Exec(ctx, q, c.ID, c.Name, c.Breed, c.Age); err != nil {
+	if _, err := tx.Exec(ctx, q, tenantID, c.ID, c.Name, c.Breed, c.Age); err != nil {
 		return toServiceError(err)
 	}
 
@@ -60,10 +88,16 @@ func (s *Storage) GetCatByID(ctx context.Context, id string) (m *cat.Cat, tErr e
 		}
 	}()
 
-	q := `SELECT * FROM cat WHERE id = $1 LIMIT 1;`
+	tenantID, err := s.scope(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `-- name: GetCat
+	SELECT id, name, breed, age FROM cat WHERE tenant_id = $1 AND id = $2 LIMIT 1;`
 
 	var model cat.Cat
-	if err := tx.QueryRow(ctx, q, id).Scan(&model.ID, &model.Name, &model.Breed, &model.Age); err != nil {
+	if err := tx.QueryRow(ctx, q, tenantID, id).Scan(&model.ID, &model.Name, &model.Breed, &model.Age); err != nil {
 		return nil, toServiceError(err)
 	}
 
@@ -74,6 +108,149 @@ func (s *Storage) GetCatByID(ctx context.Context, id string) (m *cat.Cat, tErr e
 	return &model, nil
 }
 
+func (s *Storage) ListCats(ctx context.Context, limit, offset uint32) (m []*cat.Cat, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadOnly,
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	tenantID, err := s.scope(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `-- name: ListCats
+	SELECT id, name, breed, age FROM cat WHERE tenant_id = $1 ORDER BY id LIMIT $2 OFFSET $3;`
+
+	rows, err := tx.Query(ctx, q, tenantID, limit, offset)
+	if err != nil {
+		return nil, toServiceError(err)
+	}
+	defer rows.Close()
+
+	models := make([]*cat.Cat, 0, limit)
+	for rows.Next() {
+		var model cat.Cat
+		if err := rows.Scan(&model.ID, &model.Name, &model.Breed, &model.Age); err != nil {
+			return nil, toServiceError(err)
+		}
+
+		models = append(models, &model)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, toServiceError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return models, nil
+}
+
+func (s *Storage) UpdateCat(ctx context.Context, c *cat.Cat) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	tenantID, err := s.scope(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	q := `-- name: UpdateCat
+	UPDATE cat SET name = $3, breed = $4, age = $5 WHERE tenant_id = $1 AND id = $2;`
+
+	tag, err := tx.Exec(ctx, q, tenantID, c.ID, c.Name, c.Breed, c.Age)
+	if err != nil {
+		return toServiceError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return xerr.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteCat(ctx context.Context, id string) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.Serializable, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	tenantID, err := s.scope(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	q := `-- name: DeleteCat
+	DELETE FROM cat WHERE tenant_id = $1 AND id = $2;`
+
+	tag, err := tx.Exec(ctx, q, tenantID, id)
+	if err != nil {
+		return toServiceError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return xerr.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// scope returns the tenant of the operation and, if row level security is enabled,
+// sets it for the transaction. Operations without a tenant are refused.
+func (s *Storage) scope(ctx context.Context, tx pgx.Tx) (string, error) {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("postgres: %w", tenant.ErrMissing)
+	}
+
+	if s.rls {
+		// The setting is local to the transaction, so pooled connections do not leak it.
+		if _, err := tx.Exec(ctx, `SELECT set_config('app.tenant_id', $1, true);`, tenantID); err != nil {
+			return "", fmt.Errorf("postgres: set tenant: %w", err)
+		}
+	}
+
+	return tenantID, nil
+}
+
 func toServiceError(err error) error {
 	var pgErr *pgconn.PgError
 
@@ -0,0 +1,19 @@
+package cat
+
+const (
+	// PermissionRead allows to get and list Cats.
+	PermissionRead = "cat:read"
+
+	// PermissionWrite allows to create and update Cats.
+	PermissionWrite = "cat:write"
+
+	// PermissionDelete allows to delete Cats.
+	PermissionDelete = "cat:delete"
+)
+
+// Roles holds the permissions granted by each role.
+var Roles = map[string][]string{
+	"viewer": {PermissionRead},
+	"editor": {PermissionRead, PermissionWrite},
+	"admin":  {PermissionRead, PermissionWrite, PermissionDelete},
+}
@@ -7,14 +7,36 @@ import (
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idkit"
 )
 
+const (
+	// DefaultListLimit is used by ListCats when the given limit is zero.
+	DefaultListLimit = 20
+
+	// MaxListLimit is the upper bound of the ListCats page size.
+	MaxListLimit = 100
+)
+
 // Service holds logic of work with Cat entity.
+// Each method returns ErrPermissionDenied if the caller
+// lacks the permission required by the method.
 type Service interface {
 	// GetCatByID returns a Cat searched by the given id,
 	// returns ErrNotFound in case given id can not be found.
 	GetCatByID(ctx context.Context, id string) (*Cat, error)
 
-	// CreateCat creates a Cat.
-	CreateCat(ctx context.Context, name, breed string, age uint32) error
+	// ListCats returns a page of Cats ordered by id,
+	// the page is defined by the given limit and offset.
+	ListCats(ctx context.Context, limit, offset uint32) ([]*Cat, error)
+
+	// CreateCat creates a Cat and returns it.
+	CreateCat(ctx context.Context, name, breed string, age uint32) (*Cat, error)
+
+	// UpdateCat replaces name, breed and age of the Cat with the given id,
+	// returns ErrNotFound in case given id can not be found.
+	UpdateCat(ctx context.Context, id, name, breed string, age uint32) (*Cat, error)
+
+	// DeleteCat deletes a Cat with the given id,
+	// returns ErrNotFound in case given id can not be found.
+	DeleteCat(ctx context.Context, id string) error
 }
 
 // Storage represents layer of persistence for the Cat entity.
@@ -23,8 +45,25 @@ type Storage interface {
 	// Returns ErrNotFound if Cat with given id ca not be found in the database.
 	GetCatByID(ctx context.Context, id string) (*Cat, error)
 
+	// ListCats returns at most limit Cats ordered by id, skipping the first offset records.
+	ListCats(ctx context.Context, limit, offset uint32) ([]*Cat, error)
+
 	// SaveCat saves given Cat record to the storage.
 	SaveCat(ctx context.Context, cat *Cat) error
+
+	// UpdateCat overwrites the stored Cat record with the given one.
+	// Returns ErrNotFound if Cat with given id ca not be found in the database.
+	UpdateCat(ctx context.Context, cat *Cat) error
+
+	// DeleteCat removes a Cat with the given id from the storage.
+	// Returns ErrNotFound if Cat with given id ca not be found in the database.
+	DeleteCat(ctx context.Context, id string) error
+}
+
+// Authorizer checks permissions of the caller of the context.
+type Authorizer interface {
+	// Authorize returns ErrPermissionDenied if the caller is not granted the given permission.
+	Authorize(ctx context.Context, permission string) error
 }
 
 // Cat represents a Cat entity in a context of implemented system.
@@ -37,19 +76,26 @@ type Cat struct {
 
 // ServiceImpl implements Service interface.
 type ServiceImpl struct {
-	storage Storage
+	storage    Storage
+	authorizer Authorizer
 }
 
 // NewService returns a pointer to a new instance of Service implementation.
-func NewService(storage Storage) *ServiceImpl {
+// Every operation is authorized by the given authorizer, see Permission constants.
+func NewService(storage Storage, authorizer Authorizer) *ServiceImpl {
 	s := ServiceImpl{
-		storage: storage,
+		storage:    storage,
+		authorizer: authorizer,
 	}
 
 	return &s
 }
 
 func (s *ServiceImpl) GetCatByID(ctx context.Context, id string) (*Cat, error) {
+	if err := s.authorizer.Authorize(ctx, PermissionRead); err != nil {
+		return nil, fmt.Errorf("get cat by id '%s': %w", id, err)
+	}
+
 	user, err := s.storage.GetCatByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get cat by id '%s' from the storage: %w", id, err)
@@ -58,7 +104,31 @@ func (s *ServiceImpl) GetCatByID(ctx context.Context, id string) (*Cat, error) {
 	return user, nil
 }
 
-func (s *ServiceImpl) CreateCat(ctx context.Context, name, breed string, age uint32) error {
+func (s *ServiceImpl) ListCats(ctx context.Context, limit, offset uint32) ([]*Cat, error) {
+	if err := s.authorizer.Authorize(ctx, PermissionRead); err != nil {
+		return nil, fmt.Errorf("list cats: %w", err)
+	}
+
+	switch {
+	case limit == 0:
+		limit = DefaultListLimit
+	case limit > MaxListLimit:
+		limit = MaxListLimit
+	}
+
+	cats, err := s.storage.ListCats(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list cats with limit %d and offset %d from the storage: %w", limit, offset, err)
+	}
+
+	return cats, nil
+}
+
+func (s *ServiceImpl) CreateCat(ctx context.Context, name, breed string, age uint32) (*Cat, error) {
+	if err := s.authorizer.Authorize(ctx, PermissionWrite); err != nil {
+		return nil, fmt.Errorf("create cat: %w", err)
+	}
+
 	uid := idkit.XID() // Generate new lexicographically sortable cat id.
 
 	cat := Cat{
@@ -69,7 +139,38 @@ func (s *ServiceImpl) CreateCat(ctx context.Context, name, breed string, age uin
 	}
 
 	if err := s.storage.SaveCat(ctx, &cat); err != nil {
-		return fmt.Errorf("save cat '%+v' to the storage: %w", cat, err)
+		return nil, fmt.Errorf("save cat '%+v' to the storage: %w", cat, err)
+	}
+
+	return &cat, nil
+}
+
+func (s *ServiceImpl) UpdateCat(ctx context.Context, id, name, breed string, age uint32) (*Cat, error) {
+	if err := s.authorizer.Authorize(ctx, PermissionWrite); err != nil {
+		return nil, fmt.Errorf("update cat '%s': %w", id, err)
+	}
+
+	cat := Cat{
+		ID:    id,
+		Name:  name,
+		Breed: breed,
+		Age:   age,
+	}
+
+	if err := s.storage.UpdateCat(ctx, &cat); err != nil {
+		return nil, fmt.Errorf("update cat '%+v' in the storage: %w", cat, err)
+	}
+
+	return &cat, nil
+}
+
+func (s *ServiceImpl) DeleteCat(ctx context.Context, id string) error {
+	if err := s.authorizer.Authorize(ctx, PermissionDelete); err != nil {
+		return fmt.Errorf("delete cat '%s': %w", id, err)
+	}
+
+	if err := s.storage.DeleteCat(ctx, id); err != nil {
+		return fmt.Errorf("delete cat by id '%s' from the storage: %w", id, err)
 	}
 
 	return nil
@@ -1 +1,101 @@
 package cat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestServiceImpl_authorization(t *testing.T) {
+	type tcase struct {
+		principal *auth.Principal
+
+		wantAllowed map[string]bool
+	}
+
+	operations := map[string]func(s Service, ctx context.Context) error{
+		"GetCatByID": func(s Service, ctx context.Context) error {
+			_, err := s.GetCatByID(ctx, "test-id")
+			return err
+		},
+		"ListCats": func(s Service, ctx context.Context) error {
+			_, err := s.ListCats(ctx, 0, 0)
+			return err
+		},
+		"CreateCat": func(s Service, ctx context.Context) error {
+			_, err := s.CreateCat(ctx, "test", "test-breed", 10)
+			return err
+		},
+		"UpdateCat": func(s Service, ctx context.Context) error {
+			_, err := s.UpdateCat(ctx, "test-id", "test", "test-breed", 10)
+			return err
+		},
+		"DeleteCat": func(s Service, ctx context.Context) error {
+			return s.DeleteCat(ctx, "test-id")
+		},
+	}
+
+	tests := map[string]tcase{
+		"Anonymous": {
+			principal:   nil,
+			wantAllowed: map[string]bool{},
+		},
+		"Viewer": {
+			principal:   &auth.Principal{Subject: "test", Roles: []string{"viewer"}},
+			wantAllowed: map[string]bool{"GetCatByID": true, "ListCats": true},
+		},
+		"Editor": {
+			principal: &auth.Principal{Subject: "test", Roles: []string{"editor"}},
+			wantAllowed: map[string]bool{
+				"GetCatByID": true, "ListCats": true, "CreateCat": true, "UpdateCat": true,
+			},
+		},
+		"Admin": {
+			principal: &auth.Principal{Subject: "test", Roles: []string{"admin"}},
+			wantAllowed: map[string]bool{
+				"GetCatByID": true, "ListCats": true, "CreateCat": true, "UpdateCat": true, "DeleteCat": true,
+			},
+		},
+		"Scopes": {
+			principal:   &auth.Principal{Subject: "test", Scopes: []string{PermissionRead, PermissionDelete}},
+			wantAllowed: map[string]bool{"GetCatByID": true, "ListCats": true, "DeleteCat": true},
+		},
+		"Unknown role": {
+			principal:   &auth.Principal{Subject: "test", Roles: []string{"owner"}},
+			wantAllowed: map[string]bool{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := NewService(&nopStorage{}, auth.NewPolicy(Roles))
+
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = auth.ContextWithPrincipal(ctx, tc.principal)
+			}
+
+			for op, call := range operations {
+				err := call(service, ctx)
+				td.Cmp(t, !errors.Is(err, xerr.ErrPermissionDenied), tc.wantAllowed[op], op)
+			}
+		})
+	}
+}
+
+// nopStorage implements Storage by doing nothing.
+type nopStorage struct{}
+
+func (nopStorage) GetCatByID(_ context.Context, id string) (*Cat, error) { return &Cat{ID: id}, nil }
+
+func (nopStorage) ListCats(context.Context, uint32, uint32) ([]*Cat, error) { return nil, nil }
+
+func (nopStorage) SaveCat(context.Context, *Cat) error { return nil }
+
+func (nopStorage) UpdateCat(context.Context, *Cat) error { return nil }
+
+func (nopStorage) DeleteCat(context.Context, string) error { return nil }
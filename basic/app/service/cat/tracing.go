@@ -0,0 +1,122 @@
+package cat
+
+import (
+	"context"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tracing"
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ Service = (*TracingService)(nil)
+	_ Storage = (*TracingStorage)(nil)
+)
+
+// TracingService decorates Service by a span per call, see tracing.Setup.
+type TracingService struct {
+	next Service
+}
+
+// NewTracingService returns a pointer to a new instance of TracingService.
+func NewTracingService(next Service) *TracingService {
+	return &TracingService{next: next}
+}
+
+func (s *TracingService) GetCatByID(ctx context.Context, id string) (_ *Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Service/GetCatByID", attribute.String("cat.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetCatByID(ctx, id)
+}
+
+func (s *TracingService) ListCats(ctx context.Context, limit, offset uint32) (_ []*Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Service/ListCats",
+		attribute.Int64("cat.limit", int64(limit)), attribute.Int64("cat.offset", int64(offset)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.ListCats(ctx, limit, offset)
+}
+
+func (s *TracingService) CreateCat(ctx context.Context, name, breed string, age uint32) (_ *Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Service/CreateCat")
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.CreateCat(ctx, name, breed, age)
+}
+
+func (s *TracingService) UpdateCat(ctx context.Context, id, name, breed string, age uint32) (_ *Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Service/UpdateCat", attribute.String("cat.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.UpdateCat(ctx, id, name, breed, age)
+}
+
+func (s *TracingService) DeleteCat(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "cat.Service/DeleteCat", attribute.String("cat.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.DeleteCat(ctx, id)
+}
+
+// TracingStorage decorates Storage by a span per call, see tracing.Setup.
+type TracingStorage struct {
+	next Storage
+}
+
+// NewTracingStorage returns a pointer to a new instance of TracingStorage.
+func NewTracingStorage(next Storage) *TracingStorage {
+	return &TracingStorage{next: next}
+}
+
+func (s *TracingStorage) GetCatByID(ctx context.Context, id string) (_ *Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Storage/GetCatByID", attribute.String("cat.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.GetCatByID(ctx, id)
+}
+
+func (s *TracingStorage) ListCats(ctx context.Context, limit, offset uint32) (_ []*Cat, err error) {
+	ctx, span := tracing.Start(ctx, "cat.Storage/ListCats",
+		attribute.Int64("cat.limit", int64(limit)), attribute.Int64("cat.offset", int64(offset)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.ListCats(ctx, limit, offset)
+}
+
+func (s *TracingStorage) SaveCat(ctx context.Context, cat *Cat) (err error) {
+	ctx, span := tracing.Start(ctx, "cat.Storage/SaveCat", attribute.String("cat.id", cat.ID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.SaveCat(ctx, cat)
+}
+
+func (s *TracingStorage) UpdateCat(ctx context.Context, cat *Cat) (err error) {
+	ctx, span := tracing.Start(ctx, "cat.Storage/UpdateCat", attribute.String("cat.id", cat.ID))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.UpdateCat(ctx, cat)
+}
+
+func (s *TracingStorage) DeleteCat(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "cat.Storage/DeleteCat", attribute.String("cat.id", id))
+	defer func() { tracing.End(span, err) }()
+
+	return s.next.DeleteCat(ctx, id)
+}
+
+// gqlTraced decorates the given GraphQL resolver by a span named by the field.
+func gqlTraced(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(params graphql.ResolveParams) (_ any, err error) {
+		ctx, span := tracing.Start(params.Context, "graphql/"+params.Info.FieldName,
+			attribute.String("graphql.operation.type", params.Info.Operation.GetOperation()),
+			attribute.String("graphql.field.path", params.Info.ParentType.Name()+"."+params.Info.FieldName),
+		)
+		defer func() { tracing.End(span, err) }()
+
+		params.Context = ctx
+
+		return resolve(params)
+	}
+}
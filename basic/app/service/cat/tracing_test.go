@@ -0,0 +1,30 @@
+package cat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/maxatome/go-testdeep/td"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	service := NewTracingService(&mockService{
+		getCatByIDFunc: func(context.Context, string) (*Cat, error) { return nil, xerr.ErrNotFound },
+	})
+
+	_, err := service.GetCatByID(context.Background(), "42")
+	td.CmpErrorIs(t, err, xerr.ErrNotFound)
+
+	spans := recorder.Ended()
+	td.Cmp(t, spans, td.Len(1))
+	td.Cmp(t, spans[0].Name(), "cat.Service/GetCatByID")
+	td.Cmp(t, spans[0].Status().Code, codes.Error)
+}
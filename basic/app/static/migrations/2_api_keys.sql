@@ -0,0 +1,17 @@
+create table if not exists api_keys
+(
+    id           varchar(20)                         not null,
+    name         text                                not null,
+    key_hash     bytea                               not null,
+    scopes       text[]      default '{}'            not null,
+    created_at   timestamptz default now()           not null,
+    expires_at   timestamptz,
+    revoked_at   timestamptz,
+    last_used_at timestamptz,
+    constraint api_keys_pk
+        primary key (id)
+);
+
+---- create above / drop below ----
+
+drop table if exists api_keys cascade;
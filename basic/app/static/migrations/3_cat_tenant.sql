@@ -0,0 +1,28 @@
+alter table cat
+    add column if not exists tenant_id text default 'default' not null;
+
+create index if not exists cat_tenant_id_id_index
+    on cat (tenant_id, id);
+
+-- Row level security is a second line of defence, queries filter by tenant_id anyway.
+-- The policy binds only roles which do not own the table, unless FORCE ROW LEVEL SECURITY
+-- is set, and only when the application runs with the row level security option enabled,
+-- which sets app.tenant_id for every transaction.
+alter table cat
+    enable row level security;
+
+create policy cat_tenant_isolation on cat
+    using (tenant_id = current_setting('app.tenant_id', true))
+    with check (tenant_id = current_setting('app.tenant_id', true));
+
+---- create above / drop below ----
+
+drop policy if exists cat_tenant_isolation on cat;
+
+alter table cat
+    disable row level security;
+
+drop index if exists cat_tenant_id_id_index;
+
+alter table cat
+    drop column if exists tenant_id;
@@ -0,0 +1,21 @@
+create table if not exists idempotency_keys
+(
+    scope        text                  not null,
+    key          text                  not null,
+    request_hash bytea                 not null,
+    completed    boolean default false not null,
+    status_code  int,
+    header       jsonb,
+    body         bytea,
+    created_at   timestamptz           not null,
+    expires_at   timestamptz           not null,
+    constraint idempotency_keys_pk
+        primary key (scope, key)
+);
+
+create index if not exists idempotency_keys_expires_at_index
+    on idempotency_keys (expires_at);
+
+---- create above / drop below ----
+
+drop table if exists idempotency_keys cascade;
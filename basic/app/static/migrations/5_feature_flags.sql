@@ -0,0 +1,17 @@
+create table if not exists feature_flags
+(
+    name        text                  not null,
+    description text    default ''    not null,
+    enabled     boolean default false not null,
+    tenants     text[]  default '{}'  not null,
+    principals  text[]  default '{}'  not null,
+    percentage  int     default 0     not null,
+    constraint feature_flags_pk
+        primary key (name),
+    constraint feature_flags_percentage_check
+        check (percentage between 0 and 100)
+);
+
+---- create above / drop below ----
+
+drop table if exists feature_flags cascade;
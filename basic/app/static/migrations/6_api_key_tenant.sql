@@ -0,0 +1,7 @@
+alter table api_keys
+    add column if not exists tenant_id text;
+
+---- create above / drop below ----
+
+alter table api_keys
+    drop column if exists tenant_id;
@@ -6,7 +6,7 @@ import (
 	"io/fs"
 )
 
-//go:embed migrations/*.sql
+//go:embed migrations/*.sql openapi.yaml
 var static embed.FS
 
 // Static returns all embedded static as embed.FS.
@@ -20,3 +20,13 @@ func Migrations() (fs.FS, error) {
 
 	return sub, nil
 }
+
+// OpenAPI returns the OpenAPI document of the REST API.
+func OpenAPI() ([]byte, error) {
+	doc, docErr := static.ReadFile("openapi.yaml")
+	if docErr != nil {
+		return nil, fmt.Errorf("failed to read openapi document: %w", docErr)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/apikey"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/apikey/pgapikeystore"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/urfave/cli/v2"
+)
+
+// APIKeyCommand returns the command group to manage API keys of service-to-service callers.
+func APIKeyCommand() *cli.Command {
+	var dbConnStr string
+
+	dbConnStrFlag := &cli.StringFlag{
+		Name:        "db-conn-str",
+		Usage:       "defines database connection string",
+		Required:    true,
+		Destination: &dbConnStr,
+		EnvVars:     []string{"DB_CONN_STR"},
+	}
+
+	// service connects to the database and returns the API key service.
+	service := func(c *cli.Context) (*apikey.ServiceImpl, func(), error) {
+		dbConn, err := pgxpool.New(c.Context, dbConnStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("database connection: %w", err)
+		}
+
+		return apikey.NewService(pgapikeystore.New(dbConn)), dbConn.Close, nil
+	}
+
+	create := struct {
+		Name   string
+		Tenant string
+		Scopes cli.StringSlice
+		TTL    time.Duration
+	}{}
+
+	command := cli.Command{
+		Name:  "apikey",
+		Usage: "manages API keys of service-to-service callers",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "creates an API key and prints it, the key can not be shown again",
+				Action: func(c *cli.Context) error {
+					s, closeDB, err := service(c)
+					if err != nil {
+						return err
+					}
+					defer closeDB()
+
+					key, apiKey, err := s.CreateKey(c.Context, create.Name, create.Tenant, create.Scopes.Value(), create.TTL)
+					if err != nil {
+						return fmt.Errorf("create api key: %w", err)
+					}
+
+					fmt.Printf("ID: %s\nKey: %s\n", apiKey.ID, key)
+
+					return nil
+				},
+				Flags: []cli.Flag{
+					dbConnStrFlag,
+					&cli.StringFlag{
+						Name:        "name",
+						Usage:       "defines human readable name of the key, e.g. the caller service",
+						Required:    true,
+						Destination: &create.Name,
+					},
+					&cli.StringFlag{
+						Name:        "tenant",
+						Usage:       "defines the tenant the key is bound to, keys without a tenant need the tenant:any scope to select one",
+						Destination: &create.Tenant,
+					},
+					&cli.StringSliceFlag{
+						Name:        "scope",
+						Usage:       "defines a scope granted to the key, e.g. cat:read, may be repeated",
+						Destination: &create.Scopes,
+					},
+					&cli.DurationFlag{
+						Name:        "ttl",
+						Usage:       "defines lifetime of the key, the key never expires if zero",
+						Destination: &create.TTL,
+					},
+				},
+			},
+			{
+				Name:      "revoke",
+				Usage:     "revokes the API key with the given id",
+				ArgsUsage: "<id>",
+				Action: func(c *cli.Context) error {
+					id := c.Args().First()
+					if id == "" {
+						return fmt.Errorf("api key id is required")
+					}
+
+					s, closeDB, err := service(c)
+					if err != nil {
+						return err
+					}
+					defer closeDB()
+
+					if err := s.RevokeKey(c.Context, id); err != nil {
+						return fmt.Errorf("revoke api key: %w", err)
+					}
+
+					fmt.Printf("Revoked: %s\n", id)
+
+					return nil
+				},
+				Flags: []cli.Flag{dbConnStrFlag},
+			},
+			{
+				Name:  "list",
+				Usage: "lists API keys, the keys themselves are not stored and can not be shown",
+				Action: func(c *cli.Context) error {
+					s, closeDB, err := service(c)
+					if err != nil {
+						return err
+					}
+					defer closeDB()
+
+					keys, err := s.ListKeys(c.Context)
+					if err != nil {
+						return fmt.Errorf("list api keys: %w", err)
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tNAME\tTENANT\tSCOPES\tCREATED\tEXPIRES\tREVOKED\tLAST USED")
+
+					for _, k := range keys {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+							k.ID, k.Name, formatTenant(k.Tenant), strings.Join(k.Scopes, ","), formatTime(&k.CreatedAt),
+							formatTime(k.ExpiresAt), formatTime(k.RevokedAt), formatTime(k.LastUsedAt),
+						)
+					}
+
+					return w.Flush()
+				},
+				Flags: []cli.Flag{dbConnStrFlag},
+			},
+		},
+	}
+
+	return &command
+}
+
+func formatTenant(id string) string {
+	if id == "" {
+		return "-"
+	}
+
+	return id
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
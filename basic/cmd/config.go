@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/config"
+	"github.com/urfave/cli/v2"
+)
+
+// ConfigCommand returns the command group to inspect the configuration of the serve command.
+// The subcommands accept the same flags, environment variables and config file.
+func ConfigCommand() *cli.Command {
+	printCfg, validateCfg := config.New(), config.New()
+
+	command := cli.Command{
+		Name:  "config",
+		Usage: "inspects the configuration of the serve command",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "print",
+				Usage:  "prints the effective configuration as YAML, secrets are masked",
+				Before: printCfg.Load,
+				Action: func(c *cli.Context) error {
+					return printCfg.Print(os.Stdout)
+				},
+				Flags: printCfg.Flags(),
+			},
+			{
+				Name:   "validate",
+				Usage:  "validates the effective configuration",
+				Before: validateCfg.Load,
+				Action: func(c *cli.Context) error {
+					if err := validateCfg.Validate(); err != nil {
+						return fmt.Errorf("invalid config: %w", err)
+					}
+
+					fmt.Println("Config is valid")
+
+					return nil
+				},
+				Flags: validateCfg.Flags(),
+			},
+		},
+	}
+
+	return &command
+}
@@ -2,20 +2,45 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/KitRUM/golang-blueprint/basicrest/app"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/config"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/middlewares"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/apikey"
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/apikey/pgapikeystore"
 	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat"
 	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat/pgcatstore"
 	"github.com/KitRUM/golang-blueprint/basicrest/app/static"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags/pgflagstore"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/health"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idempotency"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idempotency/pgidempotencystore"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/lifecycle"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/metrics"
 	"github.com/KitRUM/golang-blueprint/basicrest/pkg/pgmigrate"
-	"github.com/go-playground/validator/v10"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/ratelimit"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/secrets"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tracing"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
 )
 
 // Variables which are related to Version command.
@@ -35,21 +60,26 @@ var (
 )
 
 func main() {
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
 	cmd := &cli.App{
 		Name:  "app",
 		Usage: "root command of the app",
 		Before: func(c *cli.Context) error {
-			// Prints the app version.
-			fmt.Printf("Branch: %s, Commit: %s, Build time: %s\n\n", Branch, Commit, BuildTime)
+			// Prints the app version, stdout is left to the output of the commands.
+			fmt.Fprintf(os.Stderr, "Branch: %s, Commit: %s, Build time: %s\n\n", Branch, Commit, BuildTime)
 
 			return nil
 		},
 
 		Commands: []*cli.Command{
-			ServeCommand(),
+			ServeCommand(reload),
+			ConfigCommand(),
+			APIKeyCommand(),
 		},
 	}
 
@@ -58,99 +88,466 @@ func main() {
 	}
 }
 
-func ServeCommand() *cli.Command {
-	cfg := struct {
-		Env       string `validate:"oneof=dev stage prod"`
-		LogLevel  string
-		HTTPAddr  string
-		DBConnStr string
-		DBMigrate bool
-	}{}
+// ServeCommand returns the serve command, which reloads its configuration on signals received from reload.
+func ServeCommand(reload <-chan os.Signal) *cli.Command {
+	cfg := config.New()
 
 	command := cli.Command{
 		Name:  "serve",
 		Usage: "runs HTTP listener to serve the incoming connections",
 		Action: func(c *cli.Context) error {
-			logger := log.New() // Init logger.
+			logLevel, err := log.ParseLevel(cfg.LogLevel)
+			if err != nil {
+				return fmt.Errorf("parse log level: %w", err)
+			}
 
-			dbConfig, err := pgxpool.ParseConfig(cfg.DBConnStr)
+			logger := log.New(logLevel) // Init logger.
+
+			shutdownTracing, err := tracing.Setup(c.Context, tracing.Options{
+				Exporter:       tracing.Exporter(cfg.Tracing.Exporter),
+				ServiceName:    "basicrest",
+				ServiceVersion: Commit,
+				SampleRatio:    cfg.Tracing.SampleRatio,
+			})
+			if err != nil {
+				return fmt.Errorf("setup tracing: %w", err)
+			}
+
+			defer func() {
+				// The serve context is done by now, pending spans are flushed with a fresh one.
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := shutdownTracing(ctx); err != nil {
+					logger.Errorf("Failed to flush traces: %s", err.Error())
+				}
+			}()
+
+			// Components are stopped in order on shutdown, see lifecycle.Phase.
+			lc := lifecycle.New(logger, lifecycle.Options{PreStopDelay: cfg.Admin.ShutdownDrainDelay})
+
+			metricsRegistry := metrics.NewRegistry()
+
+			if err := metrics.RegisterBuildInfo(metricsRegistry, Branch, Commit, BuildTime); err != nil {
+				return err
+			}
+
+			dbConfig, err := pgxpool.ParseConfig(cfg.DB.ConnStr)
 			if err != nil {
 				return fmt.Errorf("parse database connection string: %w", err)
 			}
 
+			// Sessions of requests are named by the request ID, see pg_stat_activity.
+			appName := dbConfig.ConnConfig.RuntimeParams["application_name"]
+			if appName == "" {
+				appName = "basicrest"
+			}
+
+			if cfg.DB.MaxConns > 0 {
+				dbConfig.MaxConns = int32(cfg.DB.MaxConns)
+			}
+
+			if cfg.DB.MinConns > 0 {
+				dbConfig.MinConns = int32(cfg.DB.MinConns)
+			}
+
+			if cfg.DB.MaxConnLifetime > 0 {
+				dbConfig.MaxConnLifetime = cfg.DB.MaxConnLifetime
+			}
+
+			if cfg.DB.MaxConnIdleTime > 0 {
+				dbConfig.MaxConnIdleTime = cfg.DB.MaxConnIdleTime
+			}
+
+			dbPassword, err := dbPasswordProvider(lc, logger, cfg.DB, &dbConfig.ConnConfig.Config)
+			if err != nil {
+				return fmt.Errorf("load database password: %w", err)
+			}
+
+			if dbPassword != nil {
+				dbConfig.BeforeConnect = secrets.BeforeConnect(dbPassword)
+			}
+
+			dbConfig.ConnConfig.Tracer = requestid.NewPGXTracer(
+				appName, metrics.NewPGXTracer(metricsRegistry, tracing.NewPGXTracer()),
+			)
+
 			dbConn, err := pgxpool.NewWithConfig(c.Context, dbConfig)
 			if err != nil {
 				return fmt.Errorf("database connection: %w", err)
 			}
 
-			if cfg.DBMigrate {
-				logger.Infof("Database migration started")
+			if err := metricsRegistry.Register(metrics.NewPoolCollector(dbConn)); err != nil {
+				return fmt.Errorf("register database pool metrics: %w", err)
+			}
 
-				migrations, err := static.Migrations()
-				if err != nil {
-					return fmt.Errorf("load migrations: %w", err)
-				}
+			lc.Append(lifecycle.Hook{
+				Name:  "postgres pool",
+				Phase: lifecycle.PhaseResources,
+				Stop: func(context.Context) error {
+					dbConn.Close() // Blocks until the acquired connections are released.
+					return nil
+				},
+			})
+
+			migrations, err := static.Migrations()
+			if err != nil {
+				return fmt.Errorf("load migrations: %w", err)
+			}
+
+			if cfg.DB.Migrate {
+				logger.Infof("Database migration started")
 
 				migrator, err := pgmigrate.New(dbConn, migrations)
 				if err != nil {
 					return fmt.Errorf("create migrator: %w", err)
 				}
 
-				if err := migrator.Migrate(c.Context); err != nil {
+				// The migrator holds a connection of the pool, it is released at once.
+				if err := errors.Join(migrator.Migrate(c.Context), migrator.Close()); err != nil {
 					return fmt.Errorf("database migration: %w", err)
 				}
 
 				logger.Infof("Database migration finished")
 			}
 
-			catStorage := pgcatstore.New(dbConn)
-			catService := cat.NewService(catStorage)
-			catTransport, catTransportErr := cat.NewTransport(catService, logger)
+			healthChecks := health.NewRegistry(health.Options{
+				CacheTTL: cfg.Admin.HealthCacheTTL,
+				Metrics:  metricsRegistry,
+			})
+
+			if err := registerHealthChecks(healthChecks,
+				health.Check{Name: "postgres", Severity: health.Critical, Check: dbConn.Ping},
+				health.Check{Name: "migrations", Severity: health.Critical, Check: func(ctx context.Context) error {
+					return pgmigrate.CheckVersion(ctx, dbConn, migrations)
+				}},
+			); err != nil {
+				return err
+			}
+
+			var catStorageOpts []pgcatstore.Option
+			if cfg.DB.RowLevelSecurity {
+				catStorageOpts = append(catStorageOpts, pgcatstore.WithRowLevelSecurity())
+			}
+
+			catStorage := cat.NewTracingStorage(pgcatstore.New(dbConn, catStorageOpts...))
+			catService := cat.NewTracingService(cat.NewService(catStorage, auth.NewPolicy(cat.Roles)))
+			var catTransportOpts []cat.TransportOption
+			if !cfg.Features.GraphQL {
+				catTransportOpts = append(catTransportOpts, cat.WithoutGraphQL())
+			}
+
+			catTransport, catTransportErr := cat.NewTransport(catService, catTransportOpts...)
 			if catTransportErr != nil {
 				return fmt.Errorf("create cat transport: %w", catTransportErr)
 			}
 
-			server := app.NewServer(cfg.HTTPAddr, logger, catTransport)
+			catGRPCTransport := cat.NewGRPCTransport(catService)
 
-			return server.Serve(c.Context)
-		},
+			var flagsProvider flags.Provider = pgflagstore.New(dbConn)
+			if cfg.Features.FlagsProvider == "file" {
+				flagsProvider = flags.NewFileProvider(cfg.Features.FlagsFile)
+			}
+
+			flagsEvaluator := flags.NewEvaluator(flagsProvider)
+			if _, err := flagsEvaluator.Reload(c.Context); err != nil {
+				return fmt.Errorf("load feature flags: %w", err)
+			}
+
+			lc.Go("feature flags reloader", func(ctx context.Context) {
+				flagsEvaluator.Watch(ctx, cfg.Features.FlagsReloadInterval, logger)
+			})
+
+			openAPIDocData, err := static.OpenAPI()
+			if err != nil {
+				return fmt.Errorf("load openapi document: %w", err)
+			}
+
+			openAPIDoc, err := openapi3.NewLoader().LoadFromData(openAPIDocData)
+			if err != nil {
+				return fmt.Errorf("parse openapi document: %w", err)
+			}
+
+			if err := openAPIDoc.Validate(c.Context); err != nil {
+				return fmt.Errorf("validate openapi document: %w", err)
+			}
+
+			openAPIValidator, err := middlewares.OpenAPIValidationMiddleware(logger, openAPIDoc, middlewares.OpenAPIOptions{
+				BasePath: "/v1",
+				// Contract drift is reported to logs outside of production.
+				ValidateResponses: cfg.Env != "prod",
+			})
+			if err != nil {
+				return fmt.Errorf("create openapi validation middleware: %w", err)
+			}
+
+			// API keys of service-to-service callers are accepted along with the configured mode.
+			authExtractor := auth.Extractors{apikey.NewService(pgapikeystore.New(dbConn))}
+
+			switch cfg.Auth.Mode {
+			case "jwt":
+				authKeys, err := auth.NewJWKS(c.Context, cfg.Auth.JWKS, auth.JWKSOptions{})
+				if err != nil {
+					return fmt.Errorf("load auth key set: %w", err)
+				}
+
+				// Tokens are verified by the keys loaded before, so failing reloads only degrade the app.
+				if err := registerHealthChecks(healthChecks,
+					health.Check{Name: "jwks", Severity: health.NonCritical, Check: authKeys.Check},
+				); err != nil {
+					return err
+				}
 
-		Before: func(ctx *cli.Context) error {
-			// Config validation.
-			return validator.New().Struct(cfg)
+				authExtractor = append(authExtractor, auth.NewVerifier(authKeys, auth.VerifierOptions{
+					Issuer:   cfg.Auth.Issuer,
+					Audience: cfg.Auth.Audience,
+					Leeway:   cfg.Auth.Leeway,
+				}))
+			case "header":
+				logger.Infof("Principals are taken from %s, %s, %s and %s headers, trusted gateway is required",
+					auth.SubjectHeader, auth.ScopesHeader, auth.RolesHeader, auth.TenantHeader)
+
+				authExtractor = append(authExtractor, auth.NewHeaderExtractor())
+			}
+
+			tenantResolver := tenant.NewResolver(cfg.DefaultTenant)
+
+			idempotencyStore := pgidempotencystore.New(dbConn)
+			lc.Go("idempotency keys purger", func(ctx context.Context) {
+				purgeIdempotencyKeys(ctx, logger, idempotencyStore, cfg.IdempotencyTTL)
+			})
+
+			// The values are parsed by Validate before.
+			rateLimitRules, err := cfg.RateLimit.Rules()
+			if err != nil {
+				return err
+			}
+
+			ipRateLimitRules, err := cfg.RateLimit.IPRules()
+			if err != nil {
+				return err
+			}
+
+			trustedProxies, err := cfg.HTTP.TrustedProxyPrefixes()
+			if err != nil {
+				return err
+			}
+
+			// The rules and the origins are replaced on reload of the config.
+			dynamicRateLimitRules := ratelimit.NewDynamicRules(rateLimitRules)
+			dynamicIPRateLimitRules := ratelimit.NewDynamicRules(ipRateLimitRules)
+			rateLimitStore := ratelimit.NewMemoryStore()
+			corsOrigins := middlewares.NewCORSOrigins(cfg.CORS.Origins)
+
+			httpOpts := app.HTTPOptions{
+				CORS: middlewares.CORSOptions{
+					Origins:          corsOrigins,
+					AllowedMethods:   cfg.CORS.Methods,
+					AllowCredentials: cfg.CORS.Credentials,
+					MaxAge:           cfg.CORS.MaxAge,
+				},
+				SecurityHeaders: middlewares.SecurityHeadersOptions{HSTSMaxAge: cfg.HTTP.HSTSMaxAge},
+				TrustedProxies:  trustedProxies,
+				DisableDocs:     !cfg.Features.Docs,
+				Metrics: middlewares.MetricsOptions{
+					Namespace:        cfg.Metrics.Namespace,
+					DurationBuckets:  cfg.Metrics.DurationBuckets,
+					NativeHistograms: cfg.Metrics.NativeHistograms,
+				},
+				ReadTimeout:       cfg.HTTP.ReadTimeout,
+				ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+				WriteTimeout:      cfg.HTTP.WriteTimeout,
+				IdleTimeout:       cfg.HTTP.IdleTimeout,
+				ShutdownTimeout:   cfg.HTTP.ShutdownTimeout,
+			}
+
+			var tlsConfig *tls.Config
+
+			if cfg.TLS.Cert != "" {
+				reloader, err := certs.NewReloader(certs.Options{
+					CertFile:     cfg.TLS.Cert,
+					KeyFile:      cfg.TLS.Key,
+					ClientCAFile: cfg.TLS.ClientCA,
+					ClientAuth:   certs.ClientAuth(cfg.TLS.ClientAuth),
+				})
+				if err != nil {
+					return fmt.Errorf("load tls certificates: %w", err)
+				}
+
+				lc.Go("tls certificates reloader", func(ctx context.Context) {
+					reloader.Watch(ctx, cfg.TLS.ReloadInterval, logger)
+				})
+
+				tlsConfig = reloader.TLSConfig()
+			}
+
+			adminOpts := app.AdminOptions{
+				Addr:    cfg.Admin.Addr,
+				Metrics: metricsRegistry,
+				Health:  healthChecks,
+				Flags:   flagsEvaluator,
+				BuildInfo: app.BuildInfo{
+					Branch:    Branch,
+					Commit:    Commit,
+					BuildTime: BuildTime,
+					GoVersion: runtime.Version(),
+				},
+			}
+
+			server := app.NewServer(app.ServerOptions{
+				HTTPAddr:  cfg.HTTP.Addr,
+				GRPCAddr:  cfg.GRPC.Addr,
+				Logger:    logger,
+				TLSConfig: tlsConfig,
+				OpenAPI:   openAPIDoc,
+				Cat:       catTransport,
+				CatRPC:    catGRPCTransport,
+				HTTP:      httpOpts,
+				Admin:     adminOpts,
+				GRPCInterceptors: []grpc.UnaryServerInterceptor{
+					middlewares.GRPCAuthInterceptor(logger, authExtractor),
+					middlewares.GRPCTenantInterceptor(tenantResolver),
+					middlewares.GRPCFlagsInterceptor(flagsEvaluator),
+				},
+				V1Middlewares: []func(next http.Handler) http.Handler{
+					// Requests with guessed credentials are limited by the IP address before the authentication.
+					middlewares.IPRateLimitMiddleware(logger, metricsRegistry, rateLimitStore, dynamicIPRateLimitRules),
+					// Unauthenticated requests are rejected before the validation.
+					middlewares.AuthMiddleware(logger, authExtractor),
+					middlewares.TenantMiddleware(tenantResolver),
+					middlewares.FlagsMiddleware(flagsEvaluator),
+					// Clients are limited by the principal, so the limiter follows the authentication.
+					middlewares.RateLimitMiddleware(logger, metricsRegistry, rateLimitStore, dynamicRateLimitRules),
+					middlewares.IdempotencyMiddleware(logger, idempotencyStore, middlewares.IdempotencyOptions{
+						TTL:             cfg.IdempotencyTTL,
+						MaxBodySize:     cfg.IdempotencyMaxBodySize,
+						MaxResponseSize: cfg.IdempotencyMaxResponseSize,
+					}),
+					openAPIValidator,
+				},
+			})
+
+			watcher := config.NewWatcher(cfg, commandArgs(c), logger, func(next *config.Config) error {
+				// The values are validated by the watcher, they are parsed before any of them is applied.
+				level, err := log.ParseLevel(next.LogLevel)
+				if err != nil {
+					return fmt.Errorf("parse log level: %w", err)
+				}
+
+				rules, err := next.RateLimit.Rules()
+				if err != nil {
+					return err
+				}
+
+				ipRules, err := next.RateLimit.IPRules()
+				if err != nil {
+					return err
+				}
+
+				logger.SetLevel(level)
+				corsOrigins.Set(next.CORS.Origins)
+				dynamicRateLimitRules.Set(rules)
+				dynamicIPRateLimitRules.Set(ipRules)
+				server.SetDocs(next.Features.Docs)
+				catTransport.SetGraphQL(next.Features.GraphQL)
+
+				return nil
+			})
+
+			lc.Go("config reloader", func(ctx context.Context) {
+				watcher.Watch(ctx, reload, cfg.ReloadInterval)
+			})
+
+			if err := server.Register(lc); err != nil {
+				return err
+			}
+
+			return lc.Run(c.Context)
 		},
 
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:        "env",
-				Usage:       "defines app runtime environment",
-				EnvVars:     []string{"ENV"},
-				Value:       "dev",
-				Destination: &cfg.Env,
-			},
-			&cli.StringFlag{
-				Name:        "http-addr",
-				Usage:       "defines HTTP listener address",
-				EnvVars:     []string{"HTTP_ADDR"},
-				Destination: &cfg.HTTPAddr,
-				Value:       ":8080",
-			},
-			&cli.StringFlag{
-				Name:        "db-conn-str",
-				Usage:       "defines database connection string",
-				Required:    true,
-				Destination: &cfg.DBConnStr,
-				EnvVars:     []string{"DB_CONN_STR"},
-			},
-			&cli.BoolFlag{
-				Name:        "db-migrate",
-				Usage:       "defines whether the app should run database migrations before start",
-				Destination: &cfg.DBMigrate,
-				Value:       false,
-				EnvVars:     []string{"DB_MIGRATE"},
-			},
+		Before: func(c *cli.Context) error {
+			if err := cfg.Load(c); err != nil {
+				return err
+			}
+
+			return cfg.Validate()
 		},
+
+		Flags: cfg.Flags(),
 	}
 
 	return &command
 }
+
+// commandArgs returns the arguments given to the command of c, which follow its name
+// in the arguments left by the parent command, so flags of the parent are not part of them.
+func commandArgs(c *cli.Context) []string {
+	lineage := c.Lineage()
+	if len(lineage) < 2 {
+		return c.Args().Slice()
+	}
+
+	return lineage[1].Args().Tail()
+}
+
+// purgeIdempotencyKeys deletes expired idempotency keys every tenth of the ttl,
+// but not more often than once a minute, until ctx is done.
+func purgeIdempotencyKeys(ctx context.Context, logger log.Logger, store idempotency.Store, ttl time.Duration) {
+	ticker := time.NewTicker(max(ttl/10, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := store.DeleteExpired(ctx, time.Now().UTC())
+			if err != nil {
+				logger.Errorf("Failed to delete expired idempotency keys: %s", err.Error())
+				continue
+			}
+
+			if n > 0 {
+				logger.Infof("Deleted %d expired idempotency keys", n)
+			}
+		}
+	}
+}
+
+// dbPasswordProvider returns the provider of the database password, which is set to every new
+// connection: the watched password file, the password itself or the file defined by PGPASSFILE,
+// which is read by pgx on startup only. Returns nil if the connection string is used as is.
+func dbPasswordProvider(
+	lc *lifecycle.Manager, logger log.Logger, db config.DB, connConfig *pgconn.Config,
+) (secrets.Provider, error) {
+	switch {
+	case db.PasswordFile != "":
+		p, err := secrets.NewFileProvider(db.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+
+		lc.Go("database password reloader", func(ctx context.Context) {
+			p.Watch(ctx, secrets.DefaultReloadInterval, logger)
+		})
+
+		return p, nil
+	case db.Password != "":
+		return secrets.Static(db.Password), nil
+	case os.Getenv("PGPASSFILE") != "":
+		return secrets.NewPassfileProvider(os.Getenv("PGPASSFILE"), connConfig), nil
+	}
+
+	return nil, nil
+}
+
+// registerHealthChecks registers the checks of a component in the health registry.
+func registerHealthChecks(registry *health.Registry, checks ...health.Check) error {
+	for _, c := range checks {
+		if err := registry.Register(c); err != nil {
+			return fmt.Errorf("register health check: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package auth implements authentication of API callers by JWT bearer tokens.
+//
+// Tokens are verified by the keys of a JSON Web Key Set, which is read from
+// a local file or fetched from a URL and cached with periodic refresh,
+// so keys rotated by the identity provider are picked up without a restart.
+package auth
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+const (
+	// ErrNoCredentials indicates that the request does not carry any credentials.
+	ErrNoCredentials xerr.Error = "no credentials"
+
+	// ErrInvalidToken indicates that the token is malformed, is signed by an unknown key,
+	// has an invalid signature or its claims do not pass the validation.
+	ErrInvalidToken xerr.Error = "invalid token"
+
+	// ErrTokenExpired indicates that the token is valid but already expired.
+	ErrTokenExpired xerr.Error = "token expired"
+)
+
+// Principal represents an authenticated caller.
+type Principal struct {
+	// Subject is the identifier of the caller, the "sub" claim of the token.
+	Subject string
+
+	// Issuer is the identity provider which issued the token, the "iss" claim.
+	Issuer string
+
+	// Audience holds the recipients the token is intended for, the "aud" claim.
+	Audience []string
+
+	// Scopes holds the space separated "scope" claim of the token.
+	Scopes []string
+
+	// Roles holds the "roles" claim of the token.
+	Roles []string
+
+	// Tenant is the tenant the caller belongs to, the "tenant_id" claim of the token.
+	// Empty if the caller is not bound to a tenant, e.g. a trusted service.
+	Tenant string
+
+	// ExpiresAt is the expiration time of the token, the "exp" claim.
+	ExpiresAt time.Time
+}
+
+// HasScope reports whether the principal is granted the given scope.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the principal is assigned the given role.
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type principalCtxKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx which holds the given principal.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx by ContextWithPrincipal.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(*Principal)
+
+	return p, ok && p != nil
+}
@@ -0,0 +1,142 @@
+// Package authtest provides locally generated signing keys
+// for testing of the token authentication.
+package authtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key represents a signing key with its id.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	Signer crypto.Signer
+}
+
+// NewRSAKey generates a new RS256 signing key.
+func NewRSAKey(t testing.TB, kid string) *Key {
+	t.Helper()
+
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %s", err)
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodRS256, Signer: k}
+}
+
+// NewECKey generates a new ES256 signing key.
+func NewECKey(t testing.TB, kid string) *Key {
+	t.Helper()
+
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %s", err)
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodES256, Signer: k}
+}
+
+// NewEd25519Key generates a new EdDSA signing key.
+func NewEd25519Key(t testing.TB, kid string) *Key {
+	t.Helper()
+
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %s", err)
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Signer: k}
+}
+
+// Sign returns a token with the given claims signed by the key.
+func (k *Key) Sign(t testing.TB, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(k.Method, claims)
+	if k.ID != "" {
+		token.Header["kid"] = k.ID
+	}
+
+	s, err := token.SignedString(k.Signer)
+	if err != nil {
+		t.Fatalf("sign token: %s", err)
+	}
+
+	return s
+}
+
+// JWKS returns JSON Web Key Set document with public parts of the given keys.
+func JWKS(t testing.TB, keys ...*Key) []byte {
+	t.Helper()
+
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+
+	for _, k := range keys {
+		jwk := map[string]string{"kid": k.ID, "use": "sig", "alg": k.Method.Alg()}
+
+		switch pub := k.Signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = encode(pub.N.Bytes())
+			jwk["e"] = encode(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			point, err := pub.Bytes()
+			if err != nil {
+				t.Fatalf("encode ec key: %s", err)
+			}
+
+			size := (len(point) - 1) / 2
+
+			jwk["kty"] = "EC"
+			jwk["crv"] = pub.Curve.Params().Name
+			jwk["x"] = encode(point[1 : 1+size])
+			jwk["y"] = encode(point[1+size:])
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = encode(pub)
+		default:
+			t.Fatalf("unsupported key type %T", pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("encode jwks: %s", err)
+	}
+
+	return b
+}
+
+// WriteJWKS writes JSON Web Key Set with the given keys to the file
+// in a temporary directory and returns the path of the file.
+func WriteJWKS(t testing.TB, keys ...*Key) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, JWKS(t, keys...), 0o600); err != nil {
+		t.Fatalf("write jwks: %s", err)
+	}
+
+	return path
+}
+
+func encode(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// SubjectHeader is the header HeaderExtractor takes the principal subject from.
+	SubjectHeader = "X-Auth-Subject"
+
+	// ScopesHeader is the header HeaderExtractor takes space separated principal scopes from.
+	ScopesHeader = "X-Auth-Scopes"
+
+	// RolesHeader is the header HeaderExtractor takes space separated principal roles from.
+	RolesHeader = "X-Auth-Roles"
+
+	// TenantHeader is the header HeaderExtractor takes the principal tenant from.
+	TenantHeader = "X-Auth-Tenant"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ Extractor = (*Verifier)(nil)
+	_ Extractor = (*HeaderExtractor)(nil)
+	_ Extractor = Extractors(nil)
+)
+
+// Extractor extracts the principal from the headers of a request,
+// HTTP headers or gRPC metadata converted to http.Header.
+type Extractor interface {
+	// Extract returns the principal of the request. Returns ErrNoCredentials
+	// if the request does not carry credentials the Extractor is aware of.
+	Extract(ctx context.Context, header http.Header) (*Principal, error)
+
+	// Schemes returns the HTTP authentication schemes of the credentials the Extractor
+	// is aware of, which are challenged on failed authentication. Empty if credentials
+	// are not given by a scheme, e.g. headers of a trusted gateway.
+	Schemes() []string
+}
+
+// Extractors combines several extractors, e.g. API keys and bearer tokens.
+type Extractors []Extractor
+
+// Extract returns the principal of the first extractor which finds credentials
+// in the request. Invalid credentials are not passed to the next extractors.
+func (e Extractors) Extract(ctx context.Context, header http.Header) (*Principal, error) {
+	for _, extractor := range e {
+		p, err := extractor.Extract(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+
+		return p, err
+	}
+
+	return nil, ErrNoCredentials
+}
+
+// Schemes returns the schemes of all extractors, in their order and without duplicates.
+func (e Extractors) Schemes() []string {
+	var schemes []string
+
+	for _, extractor := range e {
+		for _, scheme := range extractor.Schemes() {
+			if !slices.Contains(schemes, scheme) {
+				schemes = append(schemes, scheme)
+			}
+		}
+	}
+
+	return schemes
+}
+
+// Extract returns the principal of the bearer token from the Authorization header.
+func (v *Verifier) Extract(ctx context.Context, header http.Header) (*Principal, error) {
+	scheme, token, _ := strings.Cut(header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	return v.Verify(ctx, token)
+}
+
+func (*Verifier) Schemes() []string {
+	return []string{"Bearer"}
+}
+
+// HeaderExtractor takes the principal from SubjectHeader, ScopesHeader,
+// RolesHeader and TenantHeader as is. It must be used only behind a trusted gateway which
+// authenticates callers and overwrites these headers.
+type HeaderExtractor struct{}
+
+// NewHeaderExtractor returns a pointer to a new instance of HeaderExtractor.
+func NewHeaderExtractor() *HeaderExtractor {
+	return &HeaderExtractor{}
+}
+
+func (*HeaderExtractor) Extract(_ context.Context, header http.Header) (*Principal, error) {
+	subject := strings.TrimSpace(header.Get(SubjectHeader))
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrNoCredentials, SubjectHeader)
+	}
+
+	p := Principal{
+		Subject: subject,
+		Scopes:  strings.Fields(header.Get(ScopesHeader)),
+		Roles:   strings.Fields(header.Get(RolesHeader)),
+		Tenant:  strings.TrimSpace(header.Get(TenantHeader)),
+	}
+
+	return &p, nil
+}
+
+func (*HeaderExtractor) Schemes() []string {
+	return nil
+}
@@ -0,0 +1,129 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestHeaderExtractor_Extract(t *testing.T) {
+	type tcase struct {
+		header http.Header
+
+		wantPrincipal *auth.Principal
+		wantErr       error
+	}
+
+	tests := map[string]tcase{
+		"Subject, scopes and roles": {
+			header: http.Header{
+				auth.SubjectHeader: {"user-1"},
+				auth.ScopesHeader:  {"cat:read  cat:write"},
+				auth.RolesHeader:   {"viewer"},
+				auth.TenantHeader:  {"tenant-1"},
+			},
+			wantPrincipal: &auth.Principal{
+				Subject: "user-1",
+				Scopes:  []string{"cat:read", "cat:write"},
+				Roles:   []string{"viewer"},
+				Tenant:  "tenant-1",
+			},
+		},
+		"Subject only": {
+			header:        http.Header{auth.SubjectHeader: {"user-1"}},
+			wantPrincipal: &auth.Principal{Subject: "user-1", Scopes: []string{}, Roles: []string{}},
+		},
+		"Missing subject": {
+			header:  http.Header{auth.ScopesHeader: {"cat:read"}},
+			wantErr: auth.ErrNoCredentials,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := auth.NewHeaderExtractor().Extract(context.Background(), tc.header)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tc.wantPrincipal)
+		})
+	}
+}
+
+func TestExtractors_Extract(t *testing.T) {
+	failing := extractorFunc(func(context.Context, http.Header) (*auth.Principal, error) {
+		return nil, auth.ErrInvalidToken
+	})
+
+	type tcase struct {
+		extractors auth.Extractors
+		header     http.Header
+
+		wantSubject string
+		wantErr     error
+	}
+
+	tests := map[string]tcase{
+		"First with credentials": {
+			extractors:  auth.Extractors{auth.NewHeaderExtractor(), failing},
+			header:      http.Header{auth.SubjectHeader: {"user-1"}},
+			wantSubject: "user-1",
+		},
+		"Skips without credentials": {
+			extractors:  auth.Extractors{&auth.Verifier{}, auth.NewHeaderExtractor()},
+			header:      http.Header{auth.SubjectHeader: {"user-1"}},
+			wantSubject: "user-1",
+		},
+		"Invalid credentials": {
+			extractors: auth.Extractors{failing, auth.NewHeaderExtractor()},
+			header:     http.Header{auth.SubjectHeader: {"user-1"}},
+			wantErr:    auth.ErrInvalidToken,
+		},
+		"No credentials": {
+			extractors: auth.Extractors{auth.NewHeaderExtractor()},
+			header:     http.Header{},
+			wantErr:    auth.ErrNoCredentials,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.extractors.Extract(context.Background(), tc.header)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got.Subject, tc.wantSubject)
+		})
+	}
+}
+
+func TestExtractors_Schemes(t *testing.T) {
+	test := extractorFunc(func(context.Context, http.Header) (*auth.Principal, error) {
+		return nil, auth.ErrNoCredentials
+	})
+
+	extractors := auth.Extractors{test, &auth.Verifier{}, auth.NewHeaderExtractor(), auth.Extractors{&auth.Verifier{}}}
+
+	td.Cmp(t, extractors.Schemes(), []string{"Test", "Bearer"})
+	td.Cmp(t, auth.Extractors{auth.NewHeaderExtractor()}.Schemes(), td.Empty())
+}
+
+type extractorFunc func(ctx context.Context, header http.Header) (*auth.Principal, error)
+
+func (f extractorFunc) Extract(ctx context.Context, header http.Header) (*auth.Principal, error) {
+	return f(ctx, header)
+}
+
+func (extractorFunc) Schemes() []string {
+	return []string{"Test"}
+}
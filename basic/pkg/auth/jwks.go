@@ -0,0 +1,423 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultRefreshInterval represents default period of the key set refresh.
+	DefaultRefreshInterval = time.Hour
+
+	// DefaultMinRefreshInterval represents default minimal period between key set
+	// refreshes which are caused by tokens signed with an unknown key.
+	DefaultMinRefreshInterval = time.Minute
+
+	// fetchTimeout represents timeout of the key set reload,
+	// it is also the timeout of the default HTTP client.
+	fetchTimeout = 10 * time.Second
+
+	// minRSAKeyBits represents minimal accepted size of RSA keys.
+	minRSAKeyBits = 2048
+
+	// maxJWKSSize represents maximal accepted size of the key set document.
+	maxJWKSSize = 1 << 20
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ KeySet = (*JWKS)(nil)
+)
+
+// KeySet provides public keys for token signature verification.
+type KeySet interface {
+	// Key returns the public key with the given key id.
+	// Returns ErrInvalidToken if there is no such key.
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JWKSOptions holds options of the JWKS.
+type JWKSOptions struct {
+	// RefreshInterval is the period after which the cached keys are reloaded.
+	// DefaultRefreshInterval is used if zero.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits how often a token with an unknown key id may
+	// cause the reload, e.g. right after the keys rotation.
+	// DefaultMinRefreshInterval is used if zero.
+	MinRefreshInterval time.Duration
+
+	// HTTPClient is used to fetch the key set by URL.
+	// The client with fetchTimeout is used if nil.
+	HTTPClient *http.Client
+}
+
+// JWKS represents a cached JSON Web Key Set.
+// More about JSON Web Key Set: https://www.rfc-editor.org/rfc/rfc7517
+//
+// RSA, EC (P-256, P-384, P-521) and OKP (Ed25519) signature keys are supported,
+// other keys of the set are ignored.
+type JWKS struct {
+	source string
+	opts   JWKSOptions
+
+	group singleflight.Group
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	checkedAt time.Time
+	err       error
+}
+
+// NewJWKS returns a pointer to a new instance of JWKS, which loads keys from
+// the given source: an http(s) URL or a path to a local file.
+// The key set is loaded immediately, so misconfiguration is reported at start.
+func NewJWKS(ctx context.Context, source string, opts JWKSOptions) (*JWKS, error) {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = DefaultRefreshInterval
+	}
+
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = DefaultMinRefreshInterval
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: fetchTimeout}
+	}
+
+	s := JWKS{
+		source: source,
+		opts:   opts,
+	}
+
+	if res := <-s.refresh(ctx); res.Err != nil {
+		return nil, res.Err
+	}
+
+	return &s, nil
+}
+
+// Key returns the public key with the given key id. The key set is reloaded
+// when the refresh interval has passed or the key id is unknown. If the reload
+// fails, the keys loaded before are still in use. A known key is returned
+// without waiting for the reload, which then runs in the background.
+//
+// Empty kid matches the only key of the set.
+func (s *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.lookup(kid)
+	sinceCheck := time.Since(s.checkedAt)
+	s.mu.RUnlock()
+
+	if sinceCheck < s.opts.RefreshInterval && (ok || sinceCheck < s.opts.MinRefreshInterval) {
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown key id '%s'", ErrInvalidToken, kid)
+		}
+
+		return key, nil
+	}
+
+	done := s.refresh(ctx)
+	if ok {
+		return key, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-done:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	}
+
+	s.mu.RLock()
+	key, ok = s.lookup(kid)
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id '%s'", ErrInvalidToken, kid)
+	}
+
+	return key, nil
+}
+
+// Check returns the error of the latest reload of the key set, nil if it succeeded.
+// The keys loaded before are still in use, so failing reloads only degrade the app.
+func (s *JWKS) Check(context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.err
+}
+
+func (s *JWKS) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+// refresh starts the reload of the key set unless it has been reloaded within
+// the minimal refresh interval, concurrent calls share a single reload.
+// The reload is detached from the cancellation of ctx and bounded by fetchTimeout,
+// so a cancelled caller does not fail it for the others. The lock is held
+// only to swap the keys, lookups of the cached keys do not wait for the fetch.
+func (s *JWKS) refresh(ctx context.Context) <-chan singleflight.Result {
+	ctx = context.WithoutCancel(ctx)
+
+	return s.group.DoChan("", func() (any, error) {
+		// The set could be reloaded by a call which has just finished.
+		s.mu.RLock()
+		recent, err := time.Since(s.checkedAt) < s.opts.MinRefreshInterval, s.err
+		s.mu.RUnlock()
+
+		if recent {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+		defer cancel()
+
+		keys, err := s.reload(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.checkedAt = time.Now()
+		s.err = err
+
+		if err == nil {
+			s.keys = keys
+		}
+
+		return nil, err
+	})
+}
+
+func (s *JWKS) reload(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load key set from '%s': %w", s.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse key set from '%s': %w", s.source, err)
+	}
+
+	return keys, nil
+}
+
+func (s *JWKS) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: unexpected status code %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	return data, nil
+}
+
+// jwk represents a single JSON Web Key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no supported signature keys")
+	}
+
+	return keys, nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key")
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.rsaPublicKey()
+	case "EC":
+		return k.ecPublicKey()
+	case "OKP":
+		return k.okpPublicKey()
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+func (k *jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeKeyParam("n", k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeKeyParam("e", k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+
+	key := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}
+
+	if key.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("rsa key is shorter than %d bits", minRSAKeyBits)
+	}
+
+	return &key, nil
+}
+
+func (k *jwk) ecPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, errUnsupportedKey
+	}
+
+	x, err := decodeKeyParam("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeKeyParam("y", k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, errors.New("invalid ec point size")
+	}
+
+	// Uncompressed point form: 0x04 || x || y.
+	point := append(append([]byte{4}, x...), y...)
+
+	key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ec point: %w", err)
+	}
+
+	return key, nil
+}
+
+func (k *jwk) okpPublicKey() (ed25519.PublicKey, error) {
+	if k.Crv != "Ed25519" {
+		return nil, errUnsupportedKey
+	}
+
+	x, err := decodeKeyParam("x", k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 key size")
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
+func decodeKeyParam(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing '%s' parameter", name)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode '%s' parameter: %w", name, err)
+	}
+
+	return b, nil
+}
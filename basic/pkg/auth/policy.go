@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+// Policy grants permissions to the principal of a context
+// by its scopes and by the permissions of its roles.
+type Policy struct {
+	roles map[string][]string
+}
+
+// NewPolicy returns a pointer to a new instance of Policy
+// with the given permissions of each role.
+func NewPolicy(roles map[string][]string) *Policy {
+	p := Policy{
+		roles: roles,
+	}
+
+	return &p
+}
+
+// Authorize checks whether the principal of ctx is granted the given permission,
+// either by a scope with the same name or by any of its roles.
+// Returns xerr.ErrPermissionDenied if it is not or ctx holds no principal.
+func (p *Policy) Authorize(ctx context.Context, permission string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: anonymous caller lacks '%s'", xerr.ErrPermissionDenied, permission)
+	}
+
+	if principal.HasScope(permission) {
+		return nil
+	}
+
+	for _, role := range principal.Roles {
+		if slices.Contains(p.roles[role], permission) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: '%s' lacks '%s'", xerr.ErrPermissionDenied, principal.Subject, permission)
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods holds the accepted token signature algorithms.
+var signingMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// VerifierOptions holds options of the Verifier.
+type VerifierOptions struct {
+	// Issuer is the expected "iss" claim of tokens.
+	// The claim is not checked if empty.
+	Issuer string
+
+	// Audience is the expected value among the "aud" claim of tokens.
+	// The claim is not checked if empty.
+	Audience string
+
+	// Leeway is the allowed clock skew for "exp" and "nbf" claims validation.
+	Leeway time.Duration
+}
+
+// Verifier verifies JWT bearer tokens and extracts principals from them.
+type Verifier struct {
+	keys   KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier returns a pointer to a new instance of Verifier,
+// which verifies token signatures by the keys of the given KeySet.
+// Tokens without "exp" claim are rejected.
+func NewVerifier(keys KeySet, opts VerifierOptions) *Verifier {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithLeeway(opts.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	v := Verifier{
+		keys:   keys,
+		parser: jwt.NewParser(parserOpts...),
+	}
+
+	return &v
+}
+
+// claims represents the claims of a token the Verifier is interested in.
+type claims struct {
+	jwt.RegisteredClaims
+
+	Scope  string   `json:"scope,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	Tenant string   `json:"tenant_id,omitempty"`
+}
+
+// Verify verifies the given token and returns the principal it is issued to.
+// Returns an error which matches ErrTokenExpired if the token is expired
+// or ErrInvalidToken if the token is not valid for any other reason.
+// Other errors indicate that the verification keys are not available.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	var keyErr error
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string) //nolint: errcheck
+
+		key, err := v.keys.Key(ctx, kid)
+		if err != nil {
+			keyErr = err
+		}
+
+		return key, err
+	}
+
+	var c claims
+
+	if _, err := v.parser.ParseWithClaims(token, &c, keyFunc); err != nil {
+		switch {
+		case keyErr != nil && !errors.Is(keyErr, ErrInvalidToken):
+			return nil, fmt.Errorf("get verification key: %w", keyErr)
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, fmt.Errorf("%w: %w", ErrTokenExpired, err)
+		default:
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+	}
+
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	p := Principal{
+		Subject:   c.Subject,
+		Issuer:    c.Issuer,
+		Audience:  c.Audience,
+		Scopes:    strings.Fields(c.Scope),
+		Roles:     c.Roles,
+		Tenant:    c.Tenant,
+		ExpiresAt: c.ExpiresAt.Time,
+	}
+
+	return &p, nil
+}
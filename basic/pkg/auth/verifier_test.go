@@ -0,0 +1,311 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth/authtest"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const (
+	testIssuer   = "https://issuer.test"
+	testAudience = "cat-api"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey := authtest.NewRSAKey(t, "rsa")
+	ecKey := authtest.NewECKey(t, "ec")
+	edKey := authtest.NewEd25519Key(t, "ed")
+	unknownKey := authtest.NewECKey(t, "unknown")
+
+	keys, err := auth.NewJWKS(context.Background(), authtest.WriteJWKS(t, rsaKey, ecKey, edKey), auth.JWKSOptions{})
+	td.CmpNoError(t, err)
+
+	verifier := auth.NewVerifier(keys, auth.VerifierOptions{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Leeway:   time.Minute,
+	})
+
+	now := time.Now()
+	exp := jwt.NewNumericDate(now.Add(time.Hour))
+
+	valid := func(mutate func(c *testClaims)) testClaims {
+		c := testClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				Issuer:    testIssuer,
+				Audience:  jwt.ClaimStrings{testAudience, "other"},
+				ExpiresAt: exp,
+			},
+			Scope: "cat:read cat:write",
+		}
+
+		if mutate != nil {
+			mutate(&c)
+		}
+
+		return c
+	}
+
+	type tcase struct {
+		token string
+
+		wantPrincipal any
+		wantErr       error
+	}
+
+	wantPrincipal := td.Struct(&auth.Principal{
+		Subject:  "user-1",
+		Issuer:   testIssuer,
+		Audience: []string{testAudience, "other"},
+		Scopes:   []string{"cat:read", "cat:write"},
+	}, td.StructFields{
+		"ExpiresAt": td.Code(func(got time.Time) bool { return got.Equal(exp.Time) }),
+	})
+
+	tests := map[string]tcase{
+		"RS256": {
+			token:         rsaKey.Sign(t, valid(nil)),
+			wantPrincipal: wantPrincipal,
+		},
+		"ES256": {
+			token:         ecKey.Sign(t, valid(nil)),
+			wantPrincipal: wantPrincipal,
+		},
+		"EdDSA": {
+			token:         edKey.Sign(t, valid(nil)),
+			wantPrincipal: wantPrincipal,
+		},
+		"Malformed": {
+			token:   "not-a-token",
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Unknown key": {
+			token:   unknownKey.Sign(t, valid(nil)),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Key id of another key": {
+			token:   (&authtest.Key{ID: "ec", Method: unknownKey.Method, Signer: unknownKey.Signer}).Sign(t, valid(nil)),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Unexpected algorithm": {
+			token:   (&authtest.Key{ID: "rsa", Method: jwt.SigningMethodRS512, Signer: rsaKey.Signer}).Sign(t, valid(nil)),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Wrong issuer": {
+			token:   rsaKey.Sign(t, valid(func(c *testClaims) { c.Issuer = "https://evil.test" })),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Wrong audience": {
+			token:   rsaKey.Sign(t, valid(func(c *testClaims) { c.Audience = jwt.ClaimStrings{"other"} })),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Expired": {
+			token: rsaKey.Sign(t, valid(func(c *testClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-2 * time.Minute))
+			})),
+			wantErr: auth.ErrTokenExpired,
+		},
+		"Expired within leeway": {
+			token: rsaKey.Sign(t, valid(func(c *testClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(now.Add(-30 * time.Second))
+			})),
+			wantPrincipal: td.Struct(&auth.Principal{Subject: "user-1"}),
+		},
+		"Not yet valid": {
+			token: rsaKey.Sign(t, valid(func(c *testClaims) {
+				c.NotBefore = jwt.NewNumericDate(now.Add(2 * time.Minute))
+			})),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Missing expiration": {
+			token:   rsaKey.Sign(t, valid(func(c *testClaims) { c.ExpiresAt = nil })),
+			wantErr: auth.ErrInvalidToken,
+		},
+		"Missing subject": {
+			token:   rsaKey.Sign(t, valid(func(c *testClaims) { c.Subject = "" })),
+			wantErr: auth.ErrInvalidToken,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := verifier.Verify(context.Background(), tc.token)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tc.wantPrincipal)
+		})
+	}
+}
+
+func TestJWKS_rotation(t *testing.T) {
+	oldKey := authtest.NewRSAKey(t, "old")
+	newKey := authtest.NewEd25519Key(t, "new")
+
+	path := authtest.WriteJWKS(t, oldKey)
+
+	keys, err := auth.NewJWKS(context.Background(), path, auth.JWKSOptions{
+		MinRefreshInterval: time.Millisecond,
+	})
+	td.CmpNoError(t, err)
+
+	_, err = keys.Key(context.Background(), "old")
+	td.CmpNoError(t, err)
+
+	td.CmpNoError(t, os.WriteFile(path, authtest.JWKS(t, newKey), 0o600))
+	time.Sleep(2 * time.Millisecond)
+
+	// Unknown key id causes the reload.
+	_, err = keys.Key(context.Background(), "new")
+	td.CmpNoError(t, err)
+
+	_, err = keys.Key(context.Background(), "old")
+	td.Cmp(t, errors.Is(err, auth.ErrInvalidToken), true)
+}
+
+func TestJWKS_url(t *testing.T) {
+	key := authtest.NewECKey(t, "ec")
+
+	var (
+		requests atomic.Int32
+		failing  atomic.Bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(authtest.JWKS(t, key)) //nolint: errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	keys, err := auth.NewJWKS(context.Background(), server.URL, auth.JWKSOptions{
+		RefreshInterval:    time.Millisecond,
+		MinRefreshInterval: time.Millisecond,
+	})
+	td.CmpNoError(t, err)
+	td.Cmp(t, requests.Load(), int32(1))
+
+	// Keys loaded before are used while the key set is not available.
+	failing.Store(true)
+	time.Sleep(2 * time.Millisecond)
+
+	_, err = keys.Key(context.Background(), "ec")
+	td.CmpNoError(t, err)
+	waitFor(t, func() bool { return keys.Check(context.Background()) != nil })
+	td.Cmp(t, requests.Load(), int32(2))
+
+	// Unknown key id is reported as a failure of the key set.
+	time.Sleep(2 * time.Millisecond)
+
+	_, err = keys.Key(context.Background(), "unknown")
+	td.CmpError(t, err)
+	td.Cmp(t, errors.Is(err, auth.ErrInvalidToken), false)
+}
+
+func TestJWKS_slowURL(t *testing.T) {
+	key := authtest.NewECKey(t, "ec")
+
+	var (
+		requests atomic.Int32
+		release  = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(authtest.JWKS(t, key)) //nolint: errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	keys, err := auth.NewJWKS(context.Background(), server.URL, auth.JWKSOptions{
+		RefreshInterval:    time.Millisecond,
+		MinRefreshInterval: time.Millisecond,
+	})
+	td.CmpNoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	// The cached key is returned while the key set is being reloaded.
+	for range 3 {
+		_, err = keys.Key(context.Background(), "ec")
+		td.CmpNoError(t, err)
+	}
+
+	// A cancelled caller stops waiting but does not abort the reload.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = keys.Key(ctx, "unknown")
+	td.Cmp(t, err, context.Canceled)
+
+	// Concurrent calls share a single reload.
+	waitFor(t, func() bool { return requests.Load() == 2 })
+	time.Sleep(2 * time.Millisecond)
+	td.Cmp(t, requests.Load(), int32(2))
+
+	close(release)
+
+	_, err = keys.Key(context.Background(), "ec")
+	td.CmpNoError(t, err)
+}
+
+func TestNewJWKS_invalid(t *testing.T) {
+	tests := map[string]string{
+		"Not json":     `keys`,
+		"Empty set":    `{"keys":[]}`,
+		"Only enc key": `{"keys":[{"kty":"OKP","crv":"Ed25519","use":"enc","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`,
+		"Short rsa":    `{"keys":[{"kty":"RSA","kid":"rsa","n":"AQAB","e":"AQAB"}]}`,
+		"Invalid ec":   `{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AQAB","y":"AQAB"}]}`,
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := t.TempDir() + "/jwks.json"
+			td.CmpNoError(t, os.WriteFile(path, []byte(doc), 0o600))
+
+			_, err := auth.NewJWKS(context.Background(), path, auth.JWKSOptions{})
+			td.CmpError(t, err)
+		})
+	}
+}
+
+type testClaims struct {
+	jwt.RegisteredClaims
+
+	Scope string `json:"scope,omitempty"`
+}
+
+// waitFor waits until cond is true, the background reload of the key set
+// has no other way to be observed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition is not met in time")
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package catclient provides a typed client of the Cat service REST API.
+//
+// The transport level code is generated from the OpenAPI document of the service
+// into the internal oapi package, this package wraps it with typed methods,
+// retries, authentication and error decoding.
+package catclient
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/catclient/internal/oapi"
+)
+
+// defaultTimeout represents default timeout of a single HTTP request.
+const defaultTimeout = 10 * time.Second
+
+// Cat represents a Cat returned by the API.
+type Cat struct {
+	ID    string
+	Name  string
+	Breed string
+	Age   uint32
+}
+
+// NewCat holds the fields of a Cat to create.
+type NewCat struct {
+	Name  string
+	Breed string
+	Age   uint32
+}
+
+// Client is a typed client of the Cat service REST API.
+type Client struct {
+	api *oapi.ClientWithResponses
+}
+
+// Option configures the Client.
+type Option func(o *options)
+
+type options struct {
+	httpClient *http.Client
+	retry      RetryPolicy
+	auth       Authenticator
+}
+
+// WithHTTPClient sets the HTTP client used to perform requests.
+func WithHTTPClient(c *http.Client) Option { return func(o *options) { o.httpClient = c } }
+
+// WithRetryPolicy sets the policy of retrying failed requests, see DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option { return func(o *options) { o.retry = p } }
+
+// WithAuth sets the Authenticator which is applied to every request attempt.
+func WithAuth(a Authenticator) Option { return func(o *options) { o.auth = a } }
+
+// New returns a pointer to a new instance of Client.
+// The baseURL must include the API version prefix, e.g. http://localhost:8080/v1.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	o := options{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	doer := &retryDoer{
+		client: o.httpClient,
+		policy: o.retry,
+		auth:   o.auth,
+	}
+
+	api, err := oapi.NewClientWithResponses(baseURL, oapi.WithHTTPClient(doer))
+	if err != nil {
+		return nil, fmt.Errorf("create api client: %w", err)
+	}
+
+	return &Client{api: api}, nil
+}
+
+// GetCat returns a Cat by the given id.
+// Returns an error which matches xerr.ErrNotFound if the Cat does not exist.
+func (c *Client) GetCat(ctx context.Context, id string) (*Cat, error) {
+	resp, err := c.api.GetCatWithResponse(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get cat by id '%s': %w", id, err)
+	}
+
+	if resp.JSON200 == nil {
+		return nil, fmt.Errorf("get cat by id '%s': %w", id, newError(resp.HTTPResponse, resp.Body))
+	}
+
+	cat := Cat{
+		ID:    resp.JSON200.Id,
+		Name:  resp.JSON200.Name,
+		Breed: resp.JSON200.Breed,
+		Age:   uint32(resp.JSON200.Age),
+	}
+
+	return &cat, nil
+}
+
+// CreateCat creates a Cat. Every call sends a fresh Idempotency-Key,
+// so retries of the call do not create duplicates.
+// Returns an error which matches xerr.ErrAlreadyExists if such Cat already exists.
+func (c *Client) CreateCat(ctx context.Context, cat NewCat) error {
+	age := int64(cat.Age)
+
+	body := oapi.NewCat{
+		Name:  cat.Name,
+		Breed: &cat.Breed,
+		Age:   &age,
+	}
+
+	key := rand.Text()
+
+	resp, err := c.api.CreateCatWithResponse(ctx, &oapi.CreateCatParams{IdempotencyKey: &key}, body)
+	if err != nil {
+		return fmt.Errorf("create cat: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("create cat: %w", newError(resp.HTTPResponse, resp.Body))
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package catclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/app/service/cat"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+	"github.com/go-chi/chi/v5"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func TestClient_GetCat(t *testing.T) {
+	storage := newMemStorage()
+	storage.cats["test-id"] = cat.Cat{ID: "test-id", Name: "test", Breed: "test-breed", Age: 10}
+
+	client := newTestClient(t, storage, nil)
+
+	t.Run("OK", func(t *testing.T) {
+		got, err := client.GetCat(context.Background(), "test-id")
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, &Cat{ID: "test-id", Name: "test", Breed: "test-breed", Age: 10})
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := client.GetCat(context.Background(), "unknown-id")
+		td.Cmp(t, errors.Is(err, xerr.ErrNotFound), true)
+
+		var apiErr *Error
+		td.Cmp(t, errors.As(err, &apiErr), true)
+		td.Cmp(t, apiErr.StatusCode, http.StatusNotFound)
+		td.Cmp(t, apiErr.Problem.Title, "Not Found")
+		td.Cmp(t, apiErr.Problem.Detail, "the cat is not found")
+	})
+}
+
+func TestClient_CreateCat(t *testing.T) {
+	storage := newMemStorage()
+	client := newTestClient(t, storage, nil)
+
+	t.Run("Created", func(t *testing.T) {
+		err := client.CreateCat(context.Background(), NewCat{Name: "test", Breed: "test-breed", Age: 10})
+		td.CmpNoError(t, err)
+		td.Cmp(t, storage.cats, td.Len(1))
+	})
+
+	t.Run("AlreadyExists", func(t *testing.T) {
+		storage.saveErr = xerr.ErrAlreadyExists
+		t.Cleanup(func() { storage.saveErr = nil })
+
+		err := client.CreateCat(context.Background(), NewCat{Name: "test"})
+		td.Cmp(t, errors.Is(err, xerr.ErrAlreadyExists), true)
+	})
+
+	t.Run("PermissionDenied", func(t *testing.T) {
+		client := newTestClient(t, storage, withPrincipal(&auth.Principal{Subject: "test", Roles: []string{"viewer"}}))
+
+		err := client.CreateCat(context.Background(), NewCat{Name: "test"})
+		td.Cmp(t, errors.Is(err, xerr.ErrPermissionDenied), true)
+	})
+}
+
+func TestClient_problemDetails(t *testing.T) {
+	client := newTestClient(t, newMemStorage(), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := problem.New(http.StatusBadRequest, "request does not match the specification")
+			p.Errors = []problem.Error{{In: "body", Name: "name", Reason: `property "name" is missing`}}
+
+			problem.Write(w, p) //nolint: errcheck
+		})
+	})
+
+	err := client.CreateCat(context.Background(), NewCat{})
+	td.Cmp(t, errors.Is(err, xerr.ErrInvalidArgument), true)
+
+	var apiErr *Error
+	td.Cmp(t, errors.As(err, &apiErr), true)
+	td.Cmp(t, apiErr.Problem, &problem.Details{
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "request does not match the specification",
+		Errors: []problem.Error{{In: "body", Name: "name", Reason: `property "name" is missing`}},
+	})
+}
+
+func TestClient_retries(t *testing.T) {
+	type tcase struct {
+		failures   int
+		failStatus int
+		call       func(c *Client) error
+
+		wantAttempts int32
+		wantErr      error
+	}
+
+	getCat := func(c *Client) error {
+		_, err := c.GetCat(context.Background(), "test-id")
+		return err
+	}
+
+	createCat := func(c *Client) error {
+		return c.CreateCat(context.Background(), NewCat{Name: "test"})
+	}
+
+	tests := map[string]tcase{
+		"GET recovers after 503": {
+			failures: 2, failStatus: http.StatusServiceUnavailable, call: getCat,
+			wantAttempts: 3,
+		},
+		"GET gives up after max attempts": {
+			failures: 5, failStatus: http.StatusBadGateway, call: getCat,
+			wantAttempts: 3,
+			wantErr:      &Error{},
+		},
+		"POST recovers after 429": {
+			failures: 1, failStatus: http.StatusTooManyRequests, call: createCat,
+			wantAttempts: 2,
+		},
+		"POST with Idempotency-Key recovers after 500": {
+			failures: 1, failStatus: http.StatusInternalServerError, call: createCat,
+			wantAttempts: 2,
+		},
+		"POST waits for the request in flight": {
+			failures: 1, failStatus: http.StatusConflict, call: createCat,
+			wantAttempts: 2,
+		},
+		"GET is not retried after 409": {
+			failures: 1, failStatus: http.StatusConflict, call: getCat,
+			wantAttempts: 1,
+			wantErr:      &Error{},
+		},
+		"Client errors are not retried": {
+			failures: 1, failStatus: http.StatusNotFound, call: getCat,
+			wantAttempts: 1,
+			wantErr:      xerr.ErrNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := newMemStorage()
+			storage.cats["test-id"] = cat.Cat{ID: "test-id", Name: "test"}
+
+			var attempts atomic.Int32
+
+			client := newTestClient(t, storage, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if attempts.Add(1) <= int32(tc.failures) {
+						w.Header().Set("Retry-After", "0")
+						http.Error(w, http.StatusText(tc.failStatus), tc.failStatus)
+						return
+					}
+
+					next.ServeHTTP(w, r)
+				})
+			})
+
+			err := tc.call(client)
+			td.Cmp(t, attempts.Load(), tc.wantAttempts)
+
+			switch want := tc.wantErr.(type) {
+			case nil:
+				td.CmpNoError(t, err)
+			case *Error:
+				td.Cmp(t, errors.As(err, &want), true)
+			default:
+				td.Cmp(t, errors.Is(err, want), true)
+			}
+		})
+	}
+}
+
+func TestClient_CreateCat_idempotencyKey(t *testing.T) {
+	var keys []string
+
+	client := newTestClient(t, newMemStorage(), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if len(keys) == 1 {
+				http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	td.CmpNoError(t, client.CreateCat(context.Background(), NewCat{Name: "test"}))
+	td.Cmp(t, keys, td.Len(2))
+	td.CmpNotEmpty(t, keys[0])
+	td.Cmp(t, keys[1], keys[0], "retries send the same key")
+}
+
+func TestClient_auth(t *testing.T) {
+	var gotAuth []string
+
+	client := newTestClient(t, newMemStorage(), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+
+			if len(gotAuth) == 1 {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, WithAuth(BearerToken("test-token")))
+
+	_, err := client.GetCat(context.Background(), "unknown-id")
+	td.Cmp(t, errors.Is(err, xerr.ErrNotFound), true)
+
+	// Every attempt is authenticated.
+	td.Cmp(t, gotAuth, []string{"Bearer test-token", "Bearer test-token"})
+}
+
+// newTestClient returns a Client of the test server which wraps cat.Transport
+// over the given storage, mounted the same way as in app.NewServer.
+func newTestClient(
+	t *testing.T, storage cat.Storage, mw func(next http.Handler) http.Handler, opts ...Option,
+) *Client {
+	t.Helper()
+
+	transport, err := cat.NewTransport(cat.NewService(storage, auth.NewPolicy(cat.Roles)))
+	td.CmpNoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(withPrincipal(&auth.Principal{Subject: "test", Roles: []string{"admin"}}))
+	router.Route("/v1", func(v1 chi.Router) {
+		if mw != nil {
+			v1.Use(mw)
+		}
+
+		v1.Mount("/cat", transport)
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() { server.Close() })
+
+	client, err := New(server.URL+"/v1", append([]Option{WithRetryPolicy(testRetryPolicy)}, opts...)...)
+	td.CmpNoError(t, err)
+
+	return client
+}
+
+func withPrincipal(p *auth.Principal) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// memStorage implements cat.Storage in memory.
+type memStorage struct {
+	mu      sync.Mutex
+	cats    map[string]cat.Cat
+	saveErr error
+}
+
+func newMemStorage() *memStorage { return &memStorage{cats: make(map[string]cat.Cat)} }
+
+func (s *memStorage) GetCatByID(_ context.Context, id string) (*cat.Cat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.cats[id]
+	if !ok {
+		return nil, xerr.ErrNotFound
+	}
+
+	return &c, nil
+}
+
+func (s *memStorage) ListCats(context.Context, uint32, uint32) ([]*cat.Cat, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memStorage) SaveCat(_ context.Context, c *cat.Cat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+
+	s.cats[c.ID] = *c
+
+	return nil
+}
+
+func (s *memStorage) UpdateCat(context.Context, *cat.Cat) error {
+	return errors.New("not implemented")
+}
+
+func (s *memStorage) DeleteCat(context.Context, string) error {
+	return errors.New("not implemented")
+}
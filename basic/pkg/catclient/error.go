@@ -0,0 +1,77 @@
+package catclient
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/problem"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ error = (*Error)(nil)
+)
+
+// Error represents an error response of the API.
+// It matches xerr errors with errors.Is by the status code of the response.
+type Error struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Problem holds problem details of the response. Plain text
+	// responses are converted to problem details with the text as a detail.
+	Problem *problem.Details
+
+	// RequestID is the ID of the request given by the API, which matches its logs.
+	RequestID string
+}
+
+func newError(res *http.Response, body []byte) *Error {
+	e := Error{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get(requestid.Header),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")) //nolint: errcheck
+
+	if mediaType == problem.ContentType {
+		var p problem.Details
+		if err := json.Unmarshal(body, &p); err == nil {
+			e.Problem = &p
+
+			return &e
+		}
+	}
+
+	e.Problem = problem.New(res.StatusCode, strings.TrimSpace(string(body)))
+
+	return &e
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return "api: " + e.Problem.Error()
+	}
+
+	return "api: " + e.Problem.Error() + " (request ID " + e.RequestID + ")"
+}
+
+// Unwrap returns the xerr error which corresponds to the status code.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return xerr.ErrInvalidArgument
+	case http.StatusForbidden:
+		return xerr.ErrPermissionDenied
+	case http.StatusNotFound:
+		return xerr.ErrNotFound
+	case http.StatusConflict:
+		return xerr.ErrAlreadyExists
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,4 @@
+// Package oapi holds the HTTP client and models generated from the OpenAPI document of the service.
+package oapi
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.8.0 --config=oapi-codegen.yaml ../../../../app/static/openapi.yaml
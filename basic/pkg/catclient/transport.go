@@ -0,0 +1,182 @@
+package catclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryPolicy is used by the Client unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+// RetryPolicy defines how failed requests are retried.
+//
+// Requests are retried on 429 Too Many Requests and 5xx responses, and on
+// network errors. Requests with non-idempotent methods, e.g. POST, are retried on
+// 5xx responses and network errors only if they carry the Idempotency-Key header.
+// Such requests are also retried on 409 Conflict with Retry-After header, which
+// reports that the first request with the same key is still in flight.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first retry, it doubles with each next attempt.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the upper bound of the delay, including the one requested by Retry-After.
+	MaxBackoff time.Duration
+}
+
+// Authenticator authenticates outgoing requests, e.g. sets the Authorization header.
+type Authenticator interface {
+	Authenticate(ctx context.Context, req *http.Request) error
+}
+
+// AuthenticatorFunc is an adapter to use ordinary functions as Authenticator.
+type AuthenticatorFunc func(ctx context.Context, req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, req *http.Request) error {
+	return f(ctx, req)
+}
+
+// BearerToken returns Authenticator which sets the given token as a bearer token.
+func BearerToken(token string) Authenticator {
+	return Header("Authorization", "Bearer "+token)
+}
+
+// Header returns Authenticator which sets the given header to the given value.
+func Header(name, value string) Authenticator {
+	return AuthenticatorFunc(func(_ context.Context, req *http.Request) error {
+		req.Header.Set(name, value)
+
+		return nil
+	})
+}
+
+// retryDoer implements oapi.HttpRequestDoer, it authenticates
+// each request attempt and retries it according to the policy.
+type retryDoer struct {
+	client *http.Client
+	policy RetryPolicy
+	auth   Authenticator
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		r, err := d.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		res, resErr := d.client.Do(r)
+
+		if attempt >= d.policy.MaxAttempts || !d.shouldRetry(req, res, resErr) {
+			return res, resErr
+		}
+
+		wait := d.backoff(attempt, res)
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body) //nolint: errcheck
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptRequest returns a copy of req with a fresh body and authentication.
+func (d *retryDoer) attemptRequest(req *http.Request, attempt int) (*http.Request, error) {
+	r := req.Clone(req.Context())
+
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewind request body: %w", err)
+		}
+
+		r.Body = body
+	}
+
+	if d.auth != nil {
+		if err := d.auth.Authenticate(r.Context(), r); err != nil {
+			return nil, fmt.Errorf("authenticate request: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (d *retryDoer) shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // Body can not be sent again.
+	}
+
+	replayable := isIdempotent(req.Method) || req.Header.Get("Idempotency-Key") != ""
+
+	if err != nil {
+		return replayable
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case res.StatusCode == http.StatusConflict && res.Header.Get("Retry-After") != "":
+		// The first request with the same Idempotency-Key is still in flight.
+		return req.Header.Get("Idempotency-Key") != ""
+	case res.StatusCode >= http.StatusInternalServerError && res.StatusCode != http.StatusNotImplemented:
+		return replayable
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before the next attempt: the delay requested by
+// Retry-After header if any, exponential backoff with jitter otherwise.
+func (d *retryDoer) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, d.policy.MaxBackoff)
+		}
+	}
+
+	wait := d.policy.MinBackoff << (attempt - 1)
+	if wait <= 0 || wait > d.policy.MaxBackoff {
+		wait = d.policy.MaxBackoff
+	}
+
+	// Full jitter spreads retries of concurrent clients over time.
+	return time.Duration(rand.Int63n(int64(wait) + 1)) //nolint: gosec
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
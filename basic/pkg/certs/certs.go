@@ -0,0 +1,241 @@
+// Package certs serves TLS certificates which are reloaded from disk on change,
+// so rotated certificates, e.g. by cert-manager, are picked up without a restart.
+// It also holds the identity of peers authenticated by client certificates.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+)
+
+// DefaultReloadInterval is the default interval of checking the files for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// ClientAuth defines whether clients must present certificates.
+type ClientAuth string
+
+const (
+	// ClientAuthRequire rejects clients without a certificate signed by the client CA.
+	ClientAuthRequire ClientAuth = "require"
+
+	// ClientAuthVerifyIfGiven accepts clients without a certificate,
+	// certificates which are given must be signed by the client CA.
+	ClientAuthVerifyIfGiven ClientAuth = "verify-if-given"
+)
+
+// Options configures Reloader.
+type Options struct {
+	// CertFile and KeyFile are PEM encoded certificate chain and private key of the server.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is PEM encoded bundle of CAs which sign client certificates.
+	// Client certificates are not requested if empty.
+	ClientCAFile string
+
+	// ClientAuth defines whether clients must present certificates, ClientAuthRequire if empty.
+	ClientAuth ClientAuth
+}
+
+// Reloader holds the certificates loaded from the files of Options.
+type Reloader struct {
+	opts Options
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	contents []byte // Contents of all the files to tell changes.
+}
+
+// NewReloader returns a pointer to a new instance of Reloader
+// with the certificates loaded from the given files.
+func NewReloader(opts Options) (*Reloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("certs: certificate and key files are required")
+	}
+
+	if opts.ClientAuth == "" {
+		opts.ClientAuth = ClientAuthRequire
+	}
+
+	if opts.ClientAuth != ClientAuthRequire && opts.ClientAuth != ClientAuthVerifyIfGiven {
+		return nil, fmt.Errorf("certs: unknown client auth '%s'", opts.ClientAuth)
+	}
+
+	r := Reloader{opts: opts}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Reload loads the certificates if the files have changed since the last load,
+// reports whether they have. The certificates in use are kept if the files are invalid.
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+
+	var contents [][]byte
+
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return false, fmt.Errorf("certs: read '%s': %w", f, err)
+		}
+
+		contents = append(contents, data)
+	}
+
+	joined := bytes.Join(contents, []byte{0})
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(joined, r.contents)
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, fmt.Errorf("certs: parse key pair: %w", err)
+	}
+
+	var clientCA *x509.CertPool
+
+	if r.opts.ClientCAFile != "" {
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(contents[2]) {
+			return false, fmt.Errorf("certs: no certificates in '%s'", r.opts.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCA, r.contents = &cert, clientCA, joined
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch reloads the certificates every interval until ctx is done.
+// Failures are logged, the certificates in use are kept.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				logger.Errorf("Failed to reload TLS certificates: %s", err.Error())
+				continue
+			}
+
+			if reloaded {
+				logger.Infof("TLS certificates reloaded")
+			}
+		}
+	}
+}
+
+// TLSConfig returns server side TLS config which serves the current certificates.
+func (r *Reloader) TLSConfig() *tls.Config {
+	cfg := tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+
+		// The client CA pool can not be changed in place, so the config is built per handshake.
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			c := tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*r.cert},
+			}
+
+			if r.clientCA != nil {
+				c.ClientCAs = r.clientCA
+				c.ClientAuth = tls.RequireAndVerifyClientCert
+
+				if r.opts.ClientAuth == ClientAuthVerifyIfGiven {
+					c.ClientAuth = tls.VerifyClientCertIfGiven
+				}
+			}
+
+			return &c, nil
+		},
+	}
+
+	return &cfg
+}
+
+// Peer represents a client authenticated by a certificate.
+type Peer struct {
+	// Subject is the common name of the certificate subject.
+	Subject string
+
+	// DNSNames and URIs are subject alternative names of the certificate,
+	// URIs hold e.g. SPIFFE IDs of workloads.
+	DNSNames []string
+	URIs     []string
+
+	// SerialNumber is the serial number of the certificate in hex.
+	SerialNumber string
+}
+
+// PeerFromCertificate returns the Peer of the verified client certificate.
+func PeerFromCertificate(cert *x509.Certificate) *Peer {
+	p := Peer{
+		Subject:      cert.Subject.CommonName,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.Text(16),
+	}
+
+	for _, u := range cert.URIs {
+		p.URIs = append(p.URIs, u.String())
+	}
+
+	return &p
+}
+
+// PeerFromConnectionState returns the Peer of the connection
+// if the client presented a verified certificate.
+func PeerFromConnectionState(state *tls.ConnectionState) (*Peer, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return PeerFromCertificate(state.VerifiedChains[0][0]), true
+}
+
+type peerCtxKey struct{}
+
+// ContextWithPeer returns a copy of ctx holding the given Peer.
+func ContextWithPeer(ctx context.Context, p *Peer) context.Context {
+	return context.WithValue(ctx, peerCtxKey{}, p)
+}
+
+// PeerFromContext returns the Peer held by ctx, if any.
+func PeerFromContext(ctx context.Context) (*Peer, bool) {
+	p, ok := ctx.Value(peerCtxKey{}).(*Peer)
+
+	return p, ok && p != nil
+}
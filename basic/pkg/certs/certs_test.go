@@ -0,0 +1,167 @@
+package certs_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/certs/certstest"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := certstest.NewCA(t, "test-ca")
+
+	write := func(leaf *certstest.Leaf) {
+		certstest.WriteFile(t, dir, "tls.crt", leaf.CertPEM())
+		certstest.WriteFile(t, dir, "tls.key", leaf.KeyPEM(t))
+	}
+
+	served := func(r *certs.Reloader) *x509.Certificate {
+		cfg, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		td.CmpNoError(t, err)
+
+		leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+		td.CmpNoError(t, err)
+
+		return leaf
+	}
+
+	first := ca.IssueServer(t)
+	write(first)
+
+	r, err := certs.NewReloader(certs.Options{CertFile: dir + "/tls.crt", KeyFile: dir + "/tls.key"})
+	td.CmpNoError(t, err)
+	td.Cmp(t, served(r).SerialNumber, first.Cert.SerialNumber)
+
+	reloaded, err := r.Reload()
+	td.CmpNoError(t, err)
+	td.Cmp(t, reloaded, false, "unchanged files are not reloaded")
+
+	second := ca.IssueServer(t)
+	write(second)
+
+	reloaded, err = r.Reload()
+	td.CmpNoError(t, err)
+	td.Cmp(t, reloaded, true)
+	td.Cmp(t, served(r).SerialNumber, second.Cert.SerialNumber)
+
+	// A half written pair, e.g. the certificate without its key, is not picked up.
+	certstest.WriteFile(t, dir, "tls.crt", ca.IssueServer(t).CertPEM())
+
+	_, err = r.Reload()
+	td.CmpError(t, err)
+	td.Cmp(t, served(r).SerialNumber, second.Cert.SerialNumber)
+}
+
+func TestNewReloader_invalid(t *testing.T) {
+	dir := t.TempDir()
+	leaf := certstest.NewCA(t, "test-ca").IssueServer(t)
+
+	certFile := certstest.WriteFile(t, dir, "tls.crt", leaf.CertPEM())
+	keyFile := certstest.WriteFile(t, dir, "tls.key", leaf.KeyPEM(t))
+	garbage := certstest.WriteFile(t, dir, "garbage.pem", []byte("garbage"))
+	otherKeyFile := certstest.WriteFile(t, dir, "other.key", certstest.NewCA(t, "other-ca").IssueServer(t).KeyPEM(t))
+
+	tests := map[string]certs.Options{
+		"Missing key":     {CertFile: certFile},
+		"Missing file":    {CertFile: certFile, KeyFile: dir + "/missing.key"},
+		"Invalid key":     {CertFile: certFile, KeyFile: garbage},
+		"Invalid CA":      {CertFile: certFile, KeyFile: keyFile, ClientCAFile: garbage},
+		"Unknown auth":    {CertFile: certFile, KeyFile: keyFile, ClientAuth: "optional"},
+		"Mismatched pair": {CertFile: certFile, KeyFile: otherKeyFile},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := certs.NewReloader(opts)
+			td.CmpError(t, err)
+		})
+	}
+}
+
+func TestReloader_mutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := certstest.NewCA(t, "test-ca")
+	server := ca.IssueServer(t)
+
+	opts := certs.Options{
+		CertFile:     certstest.WriteFile(t, dir, "tls.crt", server.CertPEM()),
+		KeyFile:      certstest.WriteFile(t, dir, "tls.key", server.KeyPEM(t)),
+		ClientCAFile: certstest.WriteFile(t, dir, "ca.crt", ca.CertPEM()),
+	}
+
+	type tcase struct {
+		clientAuth certs.ClientAuth
+		clientCert *certstest.Leaf
+
+		wantErr  bool
+		wantPeer string
+	}
+
+	tests := map[string]tcase{
+		"Client certificate": {
+			clientCert: ca.IssueClient(t, "client-1", "spiffe://example.org/client-1"),
+			wantPeer:   "client-1 [spiffe://example.org/client-1]",
+		},
+		"Missing client certificate": {
+			wantErr: true,
+		},
+		"Client certificate of other CA": {
+			clientCert: certstest.NewCA(t, "other-ca").IssueClient(t, "client-1"),
+			wantErr:    true,
+		},
+		"Optional client certificate": {
+			clientAuth: certs.ClientAuthVerifyIfGiven,
+			wantPeer:   "anonymous",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			opts.ClientAuth = tc.clientAuth
+
+			r, err := certs.NewReloader(opts)
+			td.CmpNoError(t, err)
+
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				p, ok := certs.PeerFromConnectionState(r.TLS)
+				if !ok {
+					w.Write([]byte("anonymous")) //nolint: errcheck
+					return
+				}
+
+				w.Write([]byte(p.Subject + " [" + p.URIs[0] + "]")) //nolint: errcheck
+			}))
+			srv.TLS = r.TLSConfig()
+			srv.StartTLS()
+			t.Cleanup(srv.Close)
+
+			clientTLS := tls.Config{RootCAs: ca.Pool(), MinVersion: tls.VersionTLS12}
+			if tc.clientCert != nil {
+				clientTLS.Certificates = []tls.Certificate{tc.clientCert.TLSCertificate()}
+			}
+
+			client := http.Client{Transport: &http.Transport{TLSClientConfig: &clientTLS}}
+
+			res, err := client.Get(srv.URL) //nolint: noctx
+			if tc.wantErr {
+				td.CmpError(t, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			td.CmpNoError(t, err)
+			td.Cmp(t, string(body), tc.wantPeer)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package certstest generates certificates for tests at runtime.
+package certstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// CA represents a self-signed certificate authority.
+type CA struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// NewCA returns a pointer to a new self-signed CA with the given common name.
+func NewCA(t *testing.T, name string) *CA {
+	t.Helper()
+
+	key := newKey(t)
+	tmpl := template(t, name)
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create ca certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse ca certificate: %s", err)
+	}
+
+	return &CA{Cert: cert, Key: key}
+}
+
+// Leaf represents a certificate issued by a CA along with its private key.
+type Leaf struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+}
+
+// IssueServer issues a server certificate for localhost and the loopback addresses.
+func (ca *CA) IssueServer(t *testing.T) *Leaf {
+	t.Helper()
+
+	tmpl := template(t, "localhost")
+	tmpl.DNSNames = []string{"localhost"}
+	tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	return ca.issue(t, tmpl)
+}
+
+// IssueClient issues a client certificate with the given common name and URI SANs.
+func (ca *CA) IssueClient(t *testing.T, name string, uris ...string) *Leaf {
+	t.Helper()
+
+	tmpl := template(t, name)
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	for _, u := range uris {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			t.Fatalf("parse uri: %s", err)
+		}
+
+		tmpl.URIs = append(tmpl.URIs, parsed)
+	}
+
+	return ca.issue(t, tmpl)
+}
+
+// Pool returns a pool holding the CA certificate.
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	return pool
+}
+
+// CertPEM returns PEM encoded CA certificate.
+func (ca *CA) CertPEM() []byte { return certPEM(ca.Cert) }
+
+// TLSCertificate returns the leaf as tls.Certificate.
+func (l *Leaf) TLSCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{l.Cert.Raw}, PrivateKey: l.Key, Leaf: l.Cert}
+}
+
+// CertPEM returns PEM encoded leaf certificate.
+func (l *Leaf) CertPEM() []byte { return certPEM(l.Cert) }
+
+// KeyPEM returns PEM encoded leaf private key.
+func (l *Leaf) KeyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(l.Key)
+	if err != nil {
+		t.Fatalf("marshal key: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// WriteFile writes the given data to the file with the given name in dir.
+func WriteFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %s", name, err)
+	}
+
+	return path
+}
+
+func (ca *CA) issue(t *testing.T, tmpl *x509.Certificate) *Leaf {
+	t.Helper()
+
+	key := newKey(t)
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, &key.PublicKey, ca.Key)
+	if err != nil {
+		t.Fatalf("create certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %s", err)
+	}
+
+	return &Leaf{Cert: cert, Key: key}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+
+	return key
+}
+
+func template(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		t.Fatalf("generate serial: %s", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	return &tmpl
+}
+
+func certPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ Provider = (*FileProvider)(nil)
+)
+
+// FileProvider provides the flags defined by a YAML file, which is read on every call:
+//
+//	cat.search:
+//	  description: Search of cats by name
+//	  tenants: [acme]
+//	  percentage: 10
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider returns a pointer to a new instance of FileProvider reading the file at path.
+func NewFileProvider(path string) *FileProvider {
+	p := FileProvider{path: path}
+
+	return &p
+}
+
+// Flags returns the flags defined by the file sorted by name.
+func (p *FileProvider) Flags(context.Context) ([]Flag, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("flags: read '%s': %w", p.path, err)
+	}
+
+	var defs map[string]Flag
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&defs); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("flags: parse '%s': %w", p.path, err)
+	}
+
+	flags := make([]Flag, 0, len(defs))
+
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		f := defs[name]
+		f.Name = name
+
+		if f.Percentage < 0 || f.Percentage > 100 {
+			return nil, fmt.Errorf("flags: '%s': percentage of %s must be from 0 to 100", p.path, name)
+		}
+
+		flags = append(flags, f)
+	}
+
+	return flags, nil
+}
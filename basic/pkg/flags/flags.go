@@ -0,0 +1,200 @@
+// Package flags provides feature flags, which roll out features gradually:
+// to everyone, to the listed tenants or principals, or to a percentage of them.
+//
+// Flags are read from a Provider, cached by an Evaluator and reloaded periodically,
+// so they are switched without a restart. Handlers check them by Enabled,
+// the Evaluator is put into the request context by ContextWithEvaluator.
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"maps"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+)
+
+// DefaultReloadInterval is the default interval of reloading flags from the provider.
+const DefaultReloadInterval = 30 * time.Second
+
+// Flag represents a feature flag. A flag is enabled for a request if it is enabled for everyone,
+// the tenant or the principal of the request is listed, or the request falls into the percentage.
+type Flag struct {
+	Name        string `json:"name" yaml:"-"`
+	Description string `json:"description,omitempty" yaml:"description"`
+
+	// Enabled enables the flag for everyone.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Tenants lists the tenants the flag is enabled for, see tenant.FromContext.
+	Tenants []string `json:"tenants,omitempty" yaml:"tenants"`
+
+	// Principals lists subjects of the principals the flag is enabled for, see auth.PrincipalFromContext.
+	Principals []string `json:"principals,omitempty" yaml:"principals"`
+
+	// Percentage of the principals, or of the tenants for requests without a principal,
+	// the flag is enabled for, from 0 to 100. The same principal always gets the same result,
+	// and raising the percentage keeps the flag enabled for those who already have it.
+	Percentage int `json:"percentage,omitempty" yaml:"percentage"`
+}
+
+// EnabledFor reports whether the flag is enabled for the given tenant and principal subject,
+// either of them may be empty.
+func (f *Flag) EnabledFor(tenantID, subject string) bool {
+	switch {
+	case f.Enabled:
+		return true
+	case tenantID != "" && slices.Contains(f.Tenants, tenantID):
+		return true
+	case subject != "" && slices.Contains(f.Principals, subject):
+		return true
+	}
+
+	key := subject
+	if key == "" {
+		key = tenantID
+	}
+
+	if key == "" || f.Percentage <= 0 {
+		return false
+	}
+
+	return bucket(f.Name, key) < f.Percentage
+}
+
+// bucket places the key into one of 100 buckets, separately for every flag,
+// so the same principals do not get all the flags first.
+func bucket(name, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + "/" + key)) //nolint: errcheck
+
+	return int(h.Sum32() % 100)
+}
+
+// Provider provides the feature flags, e.g. from a file or a database.
+type Provider interface {
+	Flags(ctx context.Context) ([]Flag, error)
+}
+
+// Evaluator evaluates the feature flags loaded from a provider.
+type Evaluator struct {
+	provider Provider
+
+	flags atomic.Pointer[map[string]Flag]
+}
+
+// NewEvaluator returns a pointer to a new instance of Evaluator,
+// no flag is enabled until the flags are loaded by Reload.
+func NewEvaluator(p Provider) *Evaluator {
+	e := Evaluator{provider: p}
+	e.flags.Store(&map[string]Flag{})
+
+	return &e
+}
+
+// Reload loads the flags from the provider, reports whether they have changed since the last reload.
+// The flags in use are kept if the provider fails.
+func (e *Evaluator) Reload(ctx context.Context) (bool, error) {
+	list, err := e.provider.Flags(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	flags := make(map[string]Flag, len(list))
+	for _, f := range list {
+		flags[f.Name] = f
+	}
+
+	if reflect.DeepEqual(flags, *e.flags.Load()) {
+		return false, nil
+	}
+
+	e.flags.Store(&flags)
+
+	return true, nil
+}
+
+// Watch reloads the flags every interval until ctx is done.
+// Failures are logged, the flags in use are kept.
+func (e *Evaluator) Watch(ctx context.Context, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := e.Reload(ctx)
+			if err != nil {
+				logger.Errorf("Failed to reload feature flags: %s", err.Error())
+				continue
+			}
+
+			if reloaded {
+				logger.Infof("Feature flags reloaded")
+			}
+		}
+	}
+}
+
+// Flags returns the loaded flags sorted by name.
+func (e *Evaluator) Flags() []Flag {
+	flags := *e.flags.Load()
+
+	list := make([]Flag, 0, len(flags))
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		list = append(list, flags[name])
+	}
+
+	return list
+}
+
+// Enabled reports whether the flag with the given name is enabled for the tenant and the principal
+// held by ctx, see tenant.FromContext and auth.PrincipalFromContext. Unknown flags are disabled.
+func (e *Evaluator) Enabled(ctx context.Context, name string) bool {
+	f, ok := (*e.flags.Load())[name]
+	if !ok {
+		return false
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+
+	var subject string
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		subject = p.Subject
+	}
+
+	return f.EnabledFor(tenantID, subject)
+}
+
+type evaluatorCtxKey struct{}
+
+// ContextWithEvaluator returns a copy of ctx which holds the given evaluator.
+func ContextWithEvaluator(ctx context.Context, e *Evaluator) context.Context {
+	return context.WithValue(ctx, evaluatorCtxKey{}, e)
+}
+
+// EvaluatorFromContext returns the evaluator stored in ctx by ContextWithEvaluator.
+func EvaluatorFromContext(ctx context.Context) (*Evaluator, bool) {
+	e, ok := ctx.Value(evaluatorCtxKey{}).(*Evaluator)
+
+	return e, ok && e != nil
+}
+
+// Enabled reports whether the flag with the given name is enabled for the request with ctx
+// by the evaluator held by ctx. Every flag is disabled if ctx holds no evaluator.
+func Enabled(ctx context.Context, name string) bool {
+	e, ok := EvaluatorFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	return e.Enabled(ctx, name)
+}
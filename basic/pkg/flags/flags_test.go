@@ -0,0 +1,156 @@
+package flags_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	td.CmpNoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestFlag_EnabledFor(t *testing.T) {
+	type tcase struct {
+		flag      flags.Flag
+		tenantID  string
+		principal string
+
+		want bool
+	}
+
+	tests := map[string]tcase{
+		"Enabled for everyone": {
+			flag: flags.Flag{Name: "cat.search", Enabled: true},
+			want: true,
+		},
+		"Listed tenant": {
+			flag:     flags.Flag{Name: "cat.search", Tenants: []string{"acme"}},
+			tenantID: "acme",
+			want:     true,
+		},
+		"Other tenant": {
+			flag:      flags.Flag{Name: "cat.search", Tenants: []string{"acme"}},
+			tenantID:  "globex",
+			principal: "user-1",
+			want:      false,
+		},
+		"Listed principal": {
+			flag:      flags.Flag{Name: "cat.search", Principals: []string{"user-1"}},
+			tenantID:  "globex",
+			principal: "user-1",
+			want:      true,
+		},
+		"Full percentage": {
+			flag:      flags.Flag{Name: "cat.search", Percentage: 100},
+			principal: "user-1",
+			want:      true,
+		},
+		"Percentage without tenant and principal": {
+			flag: flags.Flag{Name: "cat.search", Percentage: 100},
+			want: false,
+		},
+		"Zero percentage": {
+			flag:      flags.Flag{Name: "cat.search"},
+			principal: "user-1",
+			want:      false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			td.Cmp(t, tc.flag.EnabledFor(tc.tenantID, tc.principal), tc.want)
+		})
+	}
+}
+
+func TestFlag_EnabledFor_percentage(t *testing.T) {
+	low := flags.Flag{Name: "cat.search", Percentage: 10}
+	high := flags.Flag{Name: "cat.search", Percentage: 30}
+
+	var enabled int
+
+	for i := range 1000 {
+		subject := fmt.Sprintf("user-%d", i)
+
+		if low.EnabledFor("", subject) {
+			enabled++
+
+			td.Cmp(t, high.EnabledFor("", subject), true, "raised percentage keeps %s enabled", subject)
+		}
+	}
+
+	td.Cmp(t, enabled, td.Between(50, 150), "about 10%% of 1000 principals")
+}
+
+func TestEvaluator_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, `
+cat.search:
+  description: Search of cats by name
+  tenants: [acme]
+`)
+
+	e := flags.NewEvaluator(flags.NewFileProvider(path))
+
+	acme := tenant.ContextWithID(context.Background(), "acme")
+	td.Cmp(t, e.Enabled(acme, "cat.search"), false, "no flags before the first reload")
+
+	reloaded, err := e.Reload(context.Background())
+	td.CmpNoError(t, err)
+	td.Cmp(t, reloaded, true)
+	td.Cmp(t, e.Flags(), []flags.Flag{
+		{Name: "cat.search", Description: "Search of cats by name", Tenants: []string{"acme"}},
+	})
+	td.Cmp(t, e.Enabled(acme, "cat.search"), true)
+	td.Cmp(t, e.Enabled(acme, "cat.unknown"), false)
+
+	reloaded, err = e.Reload(context.Background())
+	td.CmpNoError(t, err)
+	td.Cmp(t, reloaded, false, "unchanged flags are not reloaded")
+
+	// The flags in use are kept if the file is invalid.
+	for content, wantErr := range map[string]string{
+		"cat.search:\n  tenant: [acme]\n":  "(?s)flags: parse .*field tenant not found",
+		"cat.search:\n  percentage: 101\n": "flags: .*percentage of cat.search must be from 0 to 100",
+	} {
+		writeFile(t, path, content)
+
+		_, err = e.Reload(context.Background())
+		td.Cmp(t, err.Error(), td.Re(wantErr))
+		td.Cmp(t, e.Enabled(acme, "cat.search"), true)
+	}
+
+	writeFile(t, path, "")
+
+	reloaded, err = e.Reload(context.Background())
+	td.CmpNoError(t, err)
+	td.Cmp(t, reloaded, true)
+	td.CmpEmpty(t, e.Flags())
+}
+
+func TestEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, "cat.search:\n  principals: [user-1]\n")
+
+	e := flags.NewEvaluator(flags.NewFileProvider(path))
+	_, err := e.Reload(context.Background())
+	td.CmpNoError(t, err)
+
+	ctx := auth.ContextWithPrincipal(context.Background(), &auth.Principal{Subject: "user-1"})
+	td.Cmp(t, flags.Enabled(ctx, "cat.search"), false, "no evaluator in the context")
+
+	ctx = flags.ContextWithEvaluator(ctx, e)
+	td.Cmp(t, flags.Enabled(ctx, "cat.search"), true)
+
+	ctx = auth.ContextWithPrincipal(ctx, &auth.Principal{Subject: "user-2"})
+	td.Cmp(t, flags.Enabled(ctx, "cat.search"), false)
+}
@@ -0,0 +1,82 @@
+package pgflagstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/flags"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ flags.Provider = (*Storage)(nil)
+)
+
+// Storage implements flags.Provider interface using Postgres.
+// There are no flags until the feature_flags table is migrated.
+type Storage struct{ conn *pgxpool.Pool }
+
+// New returns a pointer to a new instance of Storage struct.
+func New(conn *pgxpool.Pool) *Storage { return &Storage{conn: conn} }
+
+func (s *Storage) Flags(ctx context.Context) (_ []flags.Flag, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadOnly,
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `SELECT name, description, enabled, tenants, principals, percentage
+		FROM feature_flags ORDER BY name;`
+
+	rows, err := tx.Query(ctx, q)
+	switch {
+	case undefinedTable(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	defer rows.Close()
+
+	var list []flags.Flag
+	for rows.Next() {
+		var f flags.Flag
+		if err := rows.Scan(&f.Name, &f.Description, &f.Enabled, &f.Tenants, &f.Principals, &f.Percentage); err != nil {
+			return nil, fmt.Errorf("postgres: %w", err)
+		}
+
+		list = append(list, f)
+	}
+
+	switch err := rows.Err(); {
+	case undefinedTable(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return list, nil
+}
+
+// undefinedTable reports whether err is caused by a missing table, e.g. before the migration.
+func undefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable
+}
@@ -0,0 +1,238 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// DefaultTimeout limits a check which has no timeout of its own.
+	DefaultTimeout = 2 * time.Second
+
+	// DefaultCacheTTL is how long a check result is reused by the following reports.
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Severity tells how a failing check affects the status of the app.
+type Severity string
+
+const (
+	// Critical checks fail the app, it is reported down and should not receive requests.
+	Critical Severity = "critical"
+
+	// NonCritical checks degrade the app, it is still able to serve most requests.
+	NonCritical Severity = "non-critical"
+)
+
+// Status represents the status of a check or of the whole app.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Check represents a named check of a dependency, e.g. a database or an outbound client.
+type Check struct {
+	Name     string
+	Severity Severity
+
+	// Timeout limits the check, Options.DefaultTimeout is used if zero.
+	Timeout time.Duration
+
+	// Check returns an error if the dependency is not available.
+	Check func(ctx context.Context) error
+}
+
+// Result represents the latest result of a check.
+type Result struct {
+	Name      string        `json:"name"`
+	Severity  Severity      `json:"severity"`
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report represents the aggregated status of all the registered checks.
+// The app is down if any critical check fails and degraded if any non-critical one does.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Options holds options of the Registry.
+type Options struct {
+	// DefaultTimeout is used for checks without a timeout, DefaultTimeout is used if zero.
+	DefaultTimeout time.Duration
+
+	// CacheTTL is how long results are reused, so frequent probes
+	// do not hammer the dependencies. DefaultCacheTTL is used if zero.
+	CacheTTL time.Duration
+
+	// Metrics registers the health_check_status gauge, which exports the latest result
+	// of every check: 1 if it passes, 0 otherwise. The gauge is not exported if nil.
+	Metrics prometheus.Registerer
+}
+
+// Registry holds the checks registered by components of the app and their latest results.
+type Registry struct {
+	opts Options
+
+	mu      sync.Mutex
+	checks  []Check
+	results map[string]Result
+
+	// refreshMu serializes the reports, so concurrent probes run each check once.
+	refreshMu sync.Mutex
+
+	status *prometheus.GaugeVec
+
+	now func() time.Time
+}
+
+// NewRegistry returns a pointer to a new instance of Registry.
+func NewRegistry(opts Options) *Registry {
+	if opts.DefaultTimeout <= 0 {
+		opts.DefaultTimeout = DefaultTimeout
+	}
+
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+
+	r := Registry{
+		opts:    opts,
+		results: make(map[string]Result),
+		now:     time.Now,
+		status: promauto.With(opts.Metrics).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "health_check_status",
+			Help: "Result of the last run of the health check, 1 if it passes and 0 if it fails. " +
+				"The severity label is critical if a failure makes the app down, non-critical if it degrades the app.",
+		}, []string{"check", "severity"}),
+	}
+
+	return &r
+}
+
+// Register adds the check to the registry. Names of the checks must be unique.
+func (r *Registry) Register(c Check) error {
+	if c.Name == "" || c.Check == nil {
+		return errors.New("check must have a name and a function")
+	}
+
+	switch c.Severity {
+	case Critical, NonCritical:
+	default:
+		return fmt.Errorf("check %s has unknown severity '%s'", c.Name, c.Severity)
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = r.opts.DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.checks {
+		if registered.Name == c.Name {
+			return fmt.Errorf("check %s is already registered", c.Name)
+		}
+	}
+
+	r.checks = append(r.checks, c)
+
+	return nil
+}
+
+// Report runs the checks whose results are older than the cache TTL,
+// concurrently, and aggregates all the results in the order of registration.
+// The checks are bounded only by their timeouts, a caller which cancels ctx,
+// e.g. an impatient probe, does not make them fail for the cache TTL.
+func (r *Registry) Report(ctx context.Context) Report {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	r.mu.Lock()
+	now := r.now()
+
+	var stale []Check
+
+	for _, c := range r.checks {
+		if res, ok := r.results[c.Name]; !ok || now.Sub(res.CheckedAt) >= r.opts.CacheTTL {
+			stale = append(stale, c)
+		}
+	}
+	r.mu.Unlock()
+
+	fresh := make([]Result, len(stale))
+
+	var wg sync.WaitGroup
+
+	for i, c := range stale {
+		wg.Go(func() { fresh[i] = r.run(context.WithoutCancel(ctx), c) })
+	}
+
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, res := range fresh {
+		r.results[res.Name] = res
+	}
+
+	report := Report{Status: StatusUp, Checks: make([]Result, 0, len(r.checks))}
+
+	for _, c := range r.checks {
+		res := r.results[c.Name]
+
+		if res.Status == StatusDown {
+			switch {
+			case c.Severity == Critical:
+				report.Status = StatusDown
+			case report.Status == StatusUp:
+				report.Status = StatusDegraded
+			}
+		}
+
+		report.Checks = append(report.Checks, res)
+	}
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := r.now()
+	err := c.Check(ctx)
+
+	res := Result{
+		Name:      c.Name,
+		Severity:  c.Severity,
+		Status:    StatusUp,
+		Duration:  r.now().Sub(start),
+		CheckedAt: start,
+	}
+
+	status := 1.0
+
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+		status = 0
+	}
+
+	r.status.WithLabelValues(c.Name, string(c.Severity)).Set(status)
+
+	return res
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegistry_Report(t *testing.T) {
+	type tcase struct {
+		checks []Check
+
+		wantStatus Status
+		wantChecks td.TestDeep
+	}
+
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := map[string]tcase{
+		"Up": {
+			checks: []Check{
+				{Name: "postgres", Severity: Critical, Check: ok},
+				{Name: "jwks", Severity: NonCritical, Check: ok},
+			},
+			wantStatus: StatusUp,
+			wantChecks: td.Bag(
+				td.SuperJSONOf(`{"name":"postgres","status":"up"}`),
+				td.SuperJSONOf(`{"name":"jwks","status":"up"}`),
+			),
+		},
+		"Degraded": {
+			checks: []Check{
+				{Name: "postgres", Severity: Critical, Check: ok},
+				{Name: "jwks", Severity: NonCritical, Check: failing},
+			},
+			wantStatus: StatusDegraded,
+			wantChecks: td.Bag(
+				td.SuperJSONOf(`{"name":"postgres","status":"up"}`),
+				td.SuperJSONOf(`{"name":"jwks","status":"down","error":"connection refused"}`),
+			),
+		},
+		"Down": {
+			checks: []Check{
+				{Name: "postgres", Severity: Critical, Check: failing},
+				{Name: "jwks", Severity: NonCritical, Check: failing},
+			},
+			wantStatus: StatusDown,
+			wantChecks: td.Bag(
+				td.SuperJSONOf(`{"name":"postgres","status":"down"}`),
+				td.SuperJSONOf(`{"name":"jwks","status":"down"}`),
+			),
+		},
+		"Timeout": {
+			checks: []Check{
+				{Name: "postgres", Severity: Critical, Timeout: time.Millisecond, Check: func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}},
+			},
+			wantStatus: StatusDown,
+			wantChecks: td.Bag(td.SuperJSONOf(`{"name":"postgres","error":"context deadline exceeded"}`)),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRegistry(Options{})
+
+			for _, c := range tc.checks {
+				td.CmpNoError(t, r.Register(c))
+			}
+
+			report := r.Report(context.Background())
+
+			td.Cmp(t, report.Status, tc.wantStatus)
+			td.Cmp(t, report.Checks, tc.wantChecks)
+		})
+	}
+}
+
+func TestRegistry_cache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := NewRegistry(Options{CacheTTL: time.Minute})
+	r.now = func() time.Time { return now }
+
+	var calls int
+
+	td.CmpNoError(t, r.Register(Check{Name: "postgres", Severity: Critical, Check: func(context.Context) error {
+		calls++
+		return nil
+	}}))
+
+	r.Report(context.Background())
+	r.Report(context.Background())
+	td.Cmp(t, calls, 1, "result is cached")
+
+	now = now.Add(time.Minute)
+
+	r.Report(context.Background())
+	td.Cmp(t, calls, 2, "result is expired")
+
+	td.Cmp(t, testutil.ToFloat64(r.status.WithLabelValues("postgres", string(Critical))), 1.0)
+}
+
+func TestRegistry_cancelled(t *testing.T) {
+	r := NewRegistry(Options{CacheTTL: time.Minute})
+
+	td.CmpNoError(t, r.Register(Check{Name: "postgres", Severity: Critical, Check: func(ctx context.Context) error {
+		return ctx.Err()
+	}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := r.Report(ctx)
+	td.Cmp(t, report.Status, StatusUp, "cancelled probe does not fail the check")
+
+	td.Cmp(t, testutil.ToFloat64(r.status.WithLabelValues("postgres", string(Critical))), 1.0)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry(Options{})
+	ok := func(context.Context) error { return nil }
+
+	td.CmpNoError(t, r.Register(Check{Name: "postgres", Severity: Critical, Check: ok}))
+	td.CmpString(t, r.Register(Check{Name: "postgres", Severity: Critical, Check: ok}), "check postgres is already registered")
+	td.CmpString(t, r.Register(Check{Name: "cache", Check: ok}), "check cache has unknown severity ''")
+	td.CmpError(t, r.Register(Check{Name: "cache", Severity: Critical}))
+}
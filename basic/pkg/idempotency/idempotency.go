@@ -0,0 +1,84 @@
+// Package idempotency records responses of requests which carry the Idempotency-Key
+// header, so a retried request gets the original response instead of being executed again.
+// More about the header: https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+const (
+	// Header is the header a client sends the idempotency key in.
+	Header = "Idempotency-Key"
+
+	// ReplayedHeader marks responses which are replayed from a record.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// ErrExists indicates that a record with the given key already exists.
+	ErrExists xerr.Error = "idempotency: key already exists"
+
+	// ErrReservationLost indicates that the reservation of the Record is taken over
+	// by another request after it has gone stale, or released.
+	ErrReservationLost xerr.Error = "idempotency: reservation is lost"
+)
+
+// Record represents a request with an idempotency key and its response,
+// the response is empty while the request is in flight.
+type Record struct {
+	// Scope separates keys of different callers, e.g. tenants and principals.
+	Scope string
+
+	// Key is the idempotency key sent by the client.
+	Key string
+
+	// RequestHash is the hash of the request, see HashRequest.
+	RequestHash []byte
+
+	// Completed reports whether the response is recorded.
+	Completed bool
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Store persists Records.
+type Store interface {
+	// Reserve saves the given in-flight Record unless a live Record with the same scope
+	// and key exists, in that case the existing Record is returned along with ErrExists.
+	// Expired Records and in-flight Records of the same request hash created before
+	// staleBefore, whose request is considered lost, are replaced. A stale Record
+	// of another request hash is returned, so reuse of the key is still detected.
+	Reserve(ctx context.Context, r *Record, staleBefore time.Time) (*Record, error)
+
+	// Complete records the response of the reservation made by Reserve, the Record is
+	// matched by the scope, the key, the request hash and the creation time.
+	// Returns ErrReservationLost if the reservation is taken over or released.
+	Complete(ctx context.Context, r *Record) error
+
+	// Release deletes the in-flight reservation made by Reserve, matched
+	// as Complete does, so the request can be retried.
+	Release(ctx context.Context, r *Record) error
+
+	// DeleteExpired deletes Records expired before the given time,
+	// returns the number of deleted Records.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// HashRequest returns the hash of the request, which tells a retry
+// from another request reusing the same key.
+func HashRequest(method, path string, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
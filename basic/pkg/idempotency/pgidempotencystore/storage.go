@@ -0,0 +1,203 @@
+package pgidempotencystore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idempotency"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ idempotency.Store = (*Storage)(nil)
+)
+
+// Storage implements idempotency.Store interface using Postgres.
+type Storage struct{ conn *pgxpool.Pool }
+
+// New returns a pointer to a new instance of Storage struct.
+func New(conn *pgxpool.Pool) *Storage { return &Storage{conn: conn} }
+
+func (s *Storage) Reserve(
+	ctx context.Context, r *idempotency.Record, staleBefore time.Time,
+) (m *idempotency.Record, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // INSERT ... ON CONFLICT is atomic on this level.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `INSERT INTO idempotency_keys (scope, key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			request_hash = excluded.request_hash, completed = false, status_code = NULL,
+			header = NULL, body = NULL, created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= excluded.created_at
+			OR (NOT idempotency_keys.completed AND idempotency_keys.created_at < $6
+				AND idempotency_keys.request_hash = excluded.request_hash)
+		RETURNING key;`
+
+	var key string
+
+	err := tx.QueryRow(ctx, q, r.Scope, r.Key, r.RequestHash, r.CreatedAt, r.ExpiresAt, staleBefore).Scan(&key)
+	if err == nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+		}
+
+		return r, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+
+	// The conflicting record is live, it is returned to the caller.
+	q = `SELECT scope, key, request_hash, completed, status_code, header, body, created_at, expires_at
+		FROM idempotency_keys WHERE scope = $1 AND key = $2;`
+
+	existing, err := scanRecord(tx.QueryRow(ctx, q, r.Scope, r.Key))
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return existing, idempotency.ErrExists
+}
+
+func (s *Storage) Complete(ctx context.Context, r *idempotency.Record) (tErr error) {
+	header, err := json.Marshal(r.Header)
+	if err != nil {
+		return fmt.Errorf("encode header: %w", err)
+	}
+
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `UPDATE idempotency_keys SET completed = true, status_code = $5, header = $6, body = $7
+		WHERE scope = $1 AND key = $2 AND request_hash = $3 AND created_at = $4 AND NOT completed;`
+
+	tag, err := tx.Exec(ctx, q, r.Scope, r.Key, r.RequestHash, r.CreatedAt, r.StatusCode, header, r.Body)
+	if err != nil {
+		return fmt.Errorf("postgres: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return idempotency.ErrReservationLost
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) Release(ctx context.Context, r *idempotency.Record) (tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `DELETE FROM idempotency_keys
+		WHERE scope = $1 AND key = $2 AND request_hash = $3 AND created_at = $4 AND NOT completed;`
+
+	if _, err := tx.Exec(ctx, q, r.Scope, r.Key, r.RequestHash, r.CreatedAt); err != nil {
+		return fmt.Errorf("postgres: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteExpired(ctx context.Context, before time.Time) (n int64, tErr error) {
+	tx, txErr := s.conn.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted, // Consider another level of isolation for your use case.
+		AccessMode: pgx.ReadWrite,
+	})
+	if txErr != nil {
+		return 0, fmt.Errorf("begin transaction: %w", txErr)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			tErr = errors.Join(tErr, err)
+		}
+	}()
+
+	q := `DELETE FROM idempotency_keys WHERE expires_at <= $1;`
+
+	tag, err := tx.Exec(ctx, q, before)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("postgres: commit transaction: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func scanRecord(row pgx.Row) (*idempotency.Record, error) {
+	var (
+		model      idempotency.Record
+		statusCode *int
+		header     []byte
+	)
+
+	err := row.Scan(
+		&model.Scope, &model.Key, &model.RequestHash, &model.Completed,
+		&statusCode, &header, &model.Body, &model.CreatedAt, &model.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != nil {
+		model.StatusCode = *statusCode
+	}
+
+	if header != nil {
+		if err := json.Unmarshal(header, &model.Header); err != nil {
+			return nil, fmt.Errorf("decode header: %w", err)
+		}
+	}
+
+	return &model, nil
+}
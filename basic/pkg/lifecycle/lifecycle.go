@@ -0,0 +1,286 @@
+// Package lifecycle starts the components of the app and stops them in order on shutdown:
+// the app reports not ready first, waits for load balancers to notice it, drains the listeners,
+// stops background workers and closes the resources they use, e.g. database pools.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+)
+
+// DefaultStopTimeout is the default time every shutdown phase is given to complete.
+const DefaultStopTimeout = 30 * time.Second
+
+// Phase orders the stop of hooks, hooks of a phase are stopped concurrently
+// once all hooks of the previous phases have stopped.
+type Phase int
+
+const (
+	// PhaseNotReady makes the readiness probe fail, so load balancers stop routing new requests.
+	PhaseNotReady Phase = iota
+
+	// PhasePreStop waits for Options.PreStopDelay, while load balancers catch up with the readiness.
+	// Hooks can not be stopped in this phase.
+	PhasePreStop
+
+	// PhaseDrain stops the listeners once their in-flight requests complete.
+	PhaseDrain
+
+	// PhaseWorkers stops background workers, see Manager.Go.
+	PhaseWorkers
+
+	// PhaseResources closes resources used by the listeners and workers, e.g. database pools.
+	PhaseResources
+
+	// PhaseFinal stops what must outlive everything else, e.g. the listener of probes and metrics.
+	PhaseFinal
+)
+
+var phaseNames = [...]string{"not ready", "pre-stop", "drain", "workers", "resources", "final"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return fmt.Sprintf("phase %d", int(p))
+	}
+
+	return phaseNames[p]
+}
+
+// Hook represents a component of the app. Every function is optional.
+type Hook struct {
+	Name string
+
+	// Phase is the shutdown phase the hook is stopped in.
+	Phase Phase
+
+	// Start prepares the component, e.g. binds a listener. Hooks are started one by one
+	// in order of registration, a failure stops the already started hooks.
+	Start func(ctx context.Context) error
+
+	// Run runs the component once all hooks are started, e.g. serves a listener. Its context is done
+	// when the hook is stopped, the hook is considered stopped once Run returns. A failure shuts the app down.
+	Run func(ctx context.Context) error
+
+	// Stop stops the component, e.g. shuts a listener down gracefully. Its context is done after Options.StopTimeout.
+	Stop func(ctx context.Context) error
+}
+
+// Options configures Manager.
+type Options struct {
+	// PreStopDelay is how long the app reports not ready before the listeners are drained.
+	PreStopDelay time.Duration
+
+	// StopTimeout limits every shutdown phase, DefaultStopTimeout is used if zero.
+	StopTimeout time.Duration
+}
+
+// Manager runs hooks and stops them in order of phases on shutdown.
+type Manager struct {
+	logger log.Logger
+	opts   Options
+
+	hooks []Hook
+}
+
+// New returns a pointer to a new instance of Manager.
+func New(logger log.Logger, opts Options) *Manager {
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = DefaultStopTimeout
+	}
+
+	m := Manager{
+		logger: logger,
+		opts:   opts,
+	}
+
+	return &m
+}
+
+// Append registers the hooks, it must be called before Run.
+func (m *Manager) Append(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Go registers a background worker, which runs until its context is done in PhaseWorkers.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.Append(Hook{
+		Name:  name,
+		Phase: PhaseWorkers,
+		Run: func(ctx context.Context) error {
+			worker(ctx)
+			return nil
+		},
+	})
+}
+
+// runner holds the state of a running hook.
+type runner struct {
+	Hook
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Run starts the hooks, runs them until ctx is done or one of them fails and stops them in order of phases.
+// The returned error joins failures of the start, the run and the stop of the hooks.
+func (m *Manager) Run(ctx context.Context) error {
+	var (
+		runners  []*runner
+		startErr error
+	)
+
+	for _, h := range m.hooks {
+		if h.Start != nil {
+			if err := h.Start(ctx); err != nil {
+				startErr = fmt.Errorf("start %s: %w", h.Name, err)
+				break
+			}
+		}
+
+		runners = append(runners, &runner{Hook: h, done: make(chan struct{})})
+	}
+
+	failed := make(chan string, len(runners))
+
+	for _, r := range runners {
+		if r.Run == nil || startErr != nil {
+			close(r.done)
+			continue
+		}
+
+		// Runs are stopped by their phases rather than by ctx, the values of ctx are kept.
+		var runCtx context.Context
+		runCtx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+		go func() {
+			defer close(r.done)
+
+			if err := r.Run(runCtx); err != nil {
+				r.err = fmt.Errorf("run %s: %w", r.Name, err)
+				failed <- r.Name
+			}
+		}()
+	}
+
+	if startErr == nil {
+		select {
+		case <-ctx.Done():
+			m.logger.Infof("Shutdown started")
+		case name := <-failed:
+			m.logger.Errorf("Shutdown started, %s failed", name)
+		}
+	}
+
+	errs := []error{startErr, m.stop(runners)}
+
+	for _, r := range runners {
+		select {
+		case <-r.done:
+			errs = append(errs, r.err)
+		default: // The hook has not stopped in time, it is reported by stop.
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stop stops the runners phase by phase.
+func (m *Manager) stop(runners []*runner) error {
+	started := time.Now()
+
+	var errs []error
+
+	for phase := PhaseNotReady; phase <= PhaseFinal; phase++ {
+		var hooks []*runner
+
+		for _, r := range runners {
+			if r.Phase == phase {
+				hooks = append(hooks, r)
+			}
+		}
+
+		if len(hooks) == 0 && (phase != PhasePreStop || m.opts.PreStopDelay <= 0) {
+			continue
+		}
+
+		phaseStarted := time.Now()
+
+		m.logger.Infof("Shutdown phase %s started", phase)
+
+		if phase == PhasePreStop {
+			time.Sleep(m.opts.PreStopDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.opts.StopTimeout)
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+
+		for _, r := range hooks {
+			wg.Go(func() {
+				if err := m.stopHook(ctx, r); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			})
+		}
+
+		wg.Wait()
+		cancel()
+
+		elapsed := time.Since(phaseStarted)
+
+		m.logger.With(log.String("phase", phase.String()), log.Duration("duration", elapsed)).
+			Infof("Shutdown phase %s finished in %s", phase, elapsed)
+	}
+
+	elapsed := time.Since(started)
+
+	m.logger.With(log.Duration("duration", elapsed)).Infof("Shutdown finished in %s", elapsed)
+
+	return errors.Join(errs...)
+}
+
+// stopHook calls Stop of the hook, cancels its Run and waits for both until ctx is done.
+// Failures of Run are reported by Run of the Manager.
+func (m *Manager) stopHook(ctx context.Context, r *runner) error {
+	stopped := make(chan error, 1)
+
+	go func() {
+		var err error
+		if r.Stop != nil {
+			err = r.Stop(ctx)
+		}
+
+		if r.cancel != nil {
+			r.cancel()
+		}
+
+		<-r.done
+
+		stopped <- err
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			m.logger.Errorf("Failed to stop %s: %s", r.Name, err.Error())
+			return fmt.Errorf("stop %s: %w", r.Name, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		m.logger.Errorf("Failed to stop %s in %s", r.Name, m.opts.StopTimeout)
+
+		return fmt.Errorf("stop %s: %w", r.Name, ctx.Err())
+	}
+}
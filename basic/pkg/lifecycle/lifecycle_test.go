@@ -0,0 +1,153 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/lifecycle"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// recorder records events of hooks in order.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	times  map[string]time.Time
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+
+	if r.times == nil {
+		r.times = make(map[string]time.Time)
+	}
+
+	r.times[event] = time.Now()
+}
+
+// hook returns a hook which records its start and stop.
+func (r *recorder) hook(name string, phase lifecycle.Phase) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:  name,
+		Phase: phase,
+		Start: func(context.Context) error {
+			r.record("start " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	var r recorder
+
+	m := lifecycle.New(log.DisabledLogger(), lifecycle.Options{PreStopDelay: 50 * time.Millisecond})
+	m.Append(
+		r.hook("admin", lifecycle.PhaseFinal),
+		r.hook("pool", lifecycle.PhaseResources),
+		r.hook("listener", lifecycle.PhaseDrain),
+		r.hook("readiness", lifecycle.PhaseNotReady),
+	)
+	m.Go("worker", func(ctx context.Context) {
+		r.record("run worker")
+		<-ctx.Done()
+		r.record("stop worker")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+
+	// The worker runs once all hooks are started.
+	td.Cmp(t, waitEvents(&r, 5), []string{"start admin", "start pool", "start listener", "start readiness", "run worker"})
+
+	cancel()
+	td.CmpNoError(t, <-done)
+
+	td.Cmp(t, r.events[5:], []string{"stop readiness", "stop listener", "stop worker", "stop pool", "stop admin"})
+	td.Cmp(t, r.times["stop listener"].Sub(r.times["stop readiness"]), td.Gte(50*time.Millisecond),
+		"the listener is drained after the pre-stop delay")
+}
+
+// waitEvents waits for n events of r and returns them.
+func waitEvents(r *recorder, n int) []string {
+	for range 100 {
+		r.mu.Lock()
+		events := append([]string(nil), r.events...)
+		r.mu.Unlock()
+
+		if len(events) >= n {
+			return events
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+func TestManager_Run_startFailure(t *testing.T) {
+	var r recorder
+
+	failing := r.hook("listener", lifecycle.PhaseDrain)
+	failing.Start = func(context.Context) error { return errors.New("address already in use") }
+
+	ran := false
+
+	m := lifecycle.New(log.DisabledLogger(), lifecycle.Options{})
+	m.Append(r.hook("pool", lifecycle.PhaseResources), failing, r.hook("readiness", lifecycle.PhaseNotReady))
+	m.Go("worker", func(context.Context) { ran = true })
+
+	err := m.Run(context.Background())
+	td.CmpString(t, err, "start listener: address already in use")
+
+	td.Cmp(t, r.events, []string{"start pool", "stop pool"}, "only the started hooks are stopped")
+	td.Cmp(t, ran, false)
+}
+
+func TestManager_Run_runFailure(t *testing.T) {
+	var r recorder
+
+	m := lifecycle.New(log.DisabledLogger(), lifecycle.Options{})
+	m.Append(r.hook("readiness", lifecycle.PhaseNotReady), lifecycle.Hook{
+		Name:  "listener",
+		Phase: lifecycle.PhaseDrain,
+		Run:   func(context.Context) error { return errors.New("listener failed") },
+	})
+
+	// The failure shuts the app down without the cancellation of the context.
+	err := m.Run(context.Background())
+	td.CmpString(t, err, "run listener: listener failed")
+	td.Cmp(t, r.events, []string{"start readiness", "stop readiness"})
+}
+
+func TestManager_Run_stopTimeout(t *testing.T) {
+	var r recorder
+
+	stuck := r.hook("listener", lifecycle.PhaseDrain)
+	stuck.Stop = func(context.Context) error {
+		select {} // Ignores the context.
+	}
+
+	m := lifecycle.New(log.DisabledLogger(), lifecycle.Options{StopTimeout: 50 * time.Millisecond})
+	m.Append(stuck, r.hook("pool", lifecycle.PhaseResources))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.Run(ctx)
+	td.Cmp(t, errors.Is(err, context.DeadlineExceeded), true)
+	td.CmpString(t, err, "stop listener: context deadline exceeded")
+	td.Cmp(t, r.events, []string{"start listener", "start pool", "stop pool"}, "later phases are stopped anyway")
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+type loggerCtxKey struct{}
+
+type fieldsCtxKey struct{}
+
+// WithContext returns a copy of ctx holding the given Logger.
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// FromContext returns the Logger held by ctx, DisabledLogger if there is none.
+// The request scoped loggers are put into the context by the logging middlewares.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerCtxKey{}).(Logger); ok && l != nil {
+		return l
+	}
+
+	return DisabledLogger()
+}
+
+// ContextWith returns a copy of ctx holding the Logger of ctx with the given fields added.
+// The fields are also collected by the Fields of ctx, if any, see ContextWithFields.
+func ContextWith(ctx context.Context, fields ...Field) context.Context {
+	if f, ok := ctx.Value(fieldsCtxKey{}).(*Fields); ok {
+		f.Add(fields...)
+	}
+
+	return WithContext(ctx, FromContext(ctx).With(fields...))
+}
+
+// Fields collects fields added by ContextWith to the contexts derived from the one holding it,
+// so a handler logs them after the inner handlers, which add them, return.
+// It is safe for concurrent use.
+type Fields struct {
+	mu     sync.Mutex
+	fields []Field
+}
+
+// ContextWithFields returns a copy of ctx holding a new Fields, which collects
+// the fields added to ctx and to the contexts derived from it.
+func ContextWithFields(ctx context.Context) (context.Context, *Fields) {
+	f := Fields{}
+
+	return context.WithValue(ctx, fieldsCtxKey{}, &f), &f
+}
+
+// Add adds the given fields.
+func (f *Fields) Add(fields ...Field) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fields = append(f.fields, fields...)
+}
+
+// List returns the collected fields in the order they are added.
+func (f *Fields) List() []Field {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Field(nil), f.fields...)
+}
@@ -0,0 +1,30 @@
+package log
+
+import "time"
+
+// Field represents a key-value pair added to log lines, see Logger.With.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// String returns a Field holding the given string.
+func String(key, value string) Field { return Field{Key: key, Value: value} }
+
+// Int returns a Field holding the given int.
+func Int(key string, value int) Field { return Field{Key: key, Value: value} }
+
+// Int64 returns a Field holding the given int64.
+func Int64(key string, value int64) Field { return Field{Key: key, Value: value} }
+
+// Bool returns a Field holding the given bool.
+func Bool(key string, value bool) Field { return Field{Key: key, Value: value} }
+
+// Duration returns a Field holding the given duration, printed in milliseconds.
+func Duration(key string, value time.Duration) Field { return Field{Key: key, Value: value} }
+
+// Err returns a Field holding the given error under the "error" key.
+func Err(err error) Field { return Field{Key: "error", Value: err} }
+
+// Any returns a Field holding the given value, printed as JSON.
+func Any(key string, value any) Field { return Field{Key: key, Value: value} }
@@ -1,20 +1,42 @@
+// Package log provides leveled, structured logging of the application.
+// Loggers carry fields, e.g. request ID or principal, which are added to every line,
+// request scoped loggers are passed in the context, see WithContext and FromContext.
 package log
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
 // Logger abstracts the application logging logic.
 type Logger interface {
+	// Debugf formats message with given arguments and prints it with a debug level.
+	Debugf(format string, args ...any)
+
 	// Infof formats message with given arguments and prints it with an info level.
 	Infof(format string, args ...any)
 
+	// Warnf formats message with given arguments and prints it with a warn level.
+	Warnf(format string, args ...any)
+
 	// Errorf formats message with given arguments and prints it with an error level.
 	Errorf(format string, args ...any)
+
+	// With returns a child Logger which adds the given fields to every line.
+	With(fields ...Field) Logger
 }
 
+// Compilation time checks for interface implementation.
+var (
+	_ Logger = (*ZeroLogger)(nil)
+	_ Logger = disabledLogger{}
+)
+
 // DisabledLogger implements Logger interface by doing nothing.
 // Used to disabled logging in places where the Logger is used
 // as a dependency but log output should be omitted.
@@ -22,27 +44,85 @@ func DisabledLogger() Logger { return &disabledLogger{} }
 
 type disabledLogger struct{}
 
-func (disabledLogger) Infof(string, ...any)  {}
-func (disabledLogger) Errorf(string, ...any) {}
+func (disabledLogger) Debugf(string, ...any)  {}
+func (disabledLogger) Infof(string, ...any)   {}
+func (disabledLogger) Warnf(string, ...any)   {}
+func (disabledLogger) Errorf(string, ...any)  {}
+func (l disabledLogger) With(...Field) Logger { return l }
 
 // ZeroLogger implements Logger using the github.com/rs/zerolog.
 type ZeroLogger struct {
 	log zerolog.Logger
+
+	// level is shared with the child loggers, so all of them follow SetLevel.
+	level *atomic.Int32
+}
+
+// New returns a pointer to a new instance of ZeroLogger,
+// which writes JSON lines of the given level and above to stdout.
+func New(level Level) *ZeroLogger {
+	return newZeroLogger(os.Stdout, level)
 }
 
-// New returns a pointer to a new instance of ZeroLogger.
-func New() *ZeroLogger {
+func newZeroLogger(w io.Writer, level Level) *ZeroLogger {
 	l := ZeroLogger{
-		log: zerolog.New(os.Stdout).With().Timestamp().Logger(),
+		log:   zerolog.New(w).With().Timestamp().Logger(),
+		level: &atomic.Int32{},
 	}
+	l.SetLevel(level)
 
 	return &l
 }
 
-func (l *ZeroLogger) Infof(format string, args ...any) {
-	l.log.Info().Msgf(format, args...)
+// SetLevel changes the minimal level of printed lines of the logger and all its children.
+// It is safe to call concurrently with logging.
+func (l *ZeroLogger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+// Level returns the minimal level of printed lines.
+func (l *ZeroLogger) Level() Level {
+	return Level(l.level.Load())
 }
 
-func (l *ZeroLogger) Errorf(format string, args ...any) {
-	l.log.Error().Msgf(format, args...)
+func (l *ZeroLogger) Debugf(format string, args ...any) { l.logf(DebugLevel, format, args) }
+func (l *ZeroLogger) Infof(format string, args ...any)  { l.logf(InfoLevel, format, args) }
+func (l *ZeroLogger) Warnf(format string, args ...any)  { l.logf(WarnLevel, format, args) }
+func (l *ZeroLogger) Errorf(format string, args ...any) { l.logf(ErrorLevel, format, args) }
+
+func (l *ZeroLogger) With(fields ...Field) Logger {
+	c := l.log.With()
+
+	for _, f := range fields {
+		switch v := f.Value.(type) {
+		case string:
+			c = c.Str(f.Key, v)
+		case int:
+			c = c.Int(f.Key, v)
+		case int64:
+			c = c.Int64(f.Key, v)
+		case bool:
+			c = c.Bool(f.Key, v)
+		case time.Duration:
+			c = c.Dur(f.Key, v)
+		case error:
+			c = c.AnErr(f.Key, v)
+		case fmt.Stringer:
+			c = c.Stringer(f.Key, v)
+		default:
+			c = c.Interface(f.Key, v)
+		}
+	}
+
+	child := ZeroLogger{log: c.Logger(), level: l.level}
+
+	return &child
+}
+
+func (l *ZeroLogger) logf(level Level, format string, args []any) {
+	if level < l.Level() {
+		return
+	}
+
+	l.log.WithLevel(level.zerolog()).Msgf(format, args...)
 }
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestZeroLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	root := newZeroLogger(&buf, InfoLevel)
+	child := root.With(
+		String("principal", "user-1"),
+		Int("attempt", 2),
+		Duration("elapsed", 1500*time.Millisecond),
+		Err(errors.New("boom")),
+	)
+
+	lines := func() []map[string]any {
+		defer buf.Reset()
+
+		var out []map[string]any
+
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var line map[string]any
+			td.CmpNoError(t, dec.Decode(&line))
+			out = append(out, line)
+		}
+
+		return out
+	}
+
+	child.Debugf("hidden")
+	child.Warnf("cat %s", "tom")
+	td.Cmp(t, lines(), []map[string]any{{
+		"level":     "warn",
+		"message":   "cat tom",
+		"principal": "user-1",
+		"attempt":   float64(2),
+		"elapsed":   float64(1500),
+		"error":     "boom",
+		"time":      td.Ignore(),
+	}})
+
+	// Children follow the level of the root.
+	root.SetLevel(DebugLevel)
+	child.Debugf("visible")
+	td.Cmp(t, lines(), td.Len(1))
+
+	root.SetLevel(ErrorLevel)
+	child.Warnf("hidden")
+	root.Infof("hidden")
+	td.Cmp(t, lines(), td.Len(0))
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"debug": DebugLevel, "INFO": InfoLevel, "warning": WarnLevel, "error": ErrorLevel} {
+		got, err := ParseLevel(name)
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, want)
+	}
+
+	_, err := ParseLevel("trace")
+	td.CmpError(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	td.Cmp(t, FromContext(context.Background()), DisabledLogger())
+
+	var buf bytes.Buffer
+
+	ctx := WithContext(context.Background(), newZeroLogger(&buf, InfoLevel))
+	ctx = ContextWith(ctx, String("tenant", "acme"))
+
+	FromContext(ctx).Infof("hello")
+	td.CmpContains(t, buf.String(), `"tenant":"acme"`)
+}
+
+func TestContextWithFields(t *testing.T) {
+	ctx, fields := ContextWithFields(context.Background())
+
+	// Fields added to derived contexts are collected as well.
+	inner := ContextWith(ctx, String("principal", "user-1"))
+	ContextWith(inner, String("tenant", "acme"))
+
+	td.Cmp(t, fields.List(), []Field{String("principal", "user-1"), String("tenant", "acme")})
+
+	ContextWith(context.Background(), String("tenant", "globex"))
+	td.Cmp(t, fields.List(), td.Len(2))
+}
@@ -0,0 +1,44 @@
+// Package metrics provides Prometheus collectors of the app dependencies.
+// Metrics are registered in a registry created by the app instead of the
+// global one, so tests and several servers in a process don't collide.
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewRegistry returns a pointer to a new instance of prometheus.Registry
+// with the Go runtime and process collectors registered.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
+
+// RegisterBuildInfo registers the build_info gauge, which is always 1
+// and labelled with the version of the binary injected at build time.
+func RegisterBuildInfo(reg prometheus.Registerer, branch, commit, buildTime string) error {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Version of the binary, the value is always 1.",
+		ConstLabels: prometheus.Labels{
+			"branch":     branch,
+			"commit":     commit,
+			"build_time": buildTime,
+		},
+	})
+	gauge.Set(1)
+
+	if err := reg.Register(gauge); err != nil {
+		return fmt.Errorf("register build info: %w", err)
+	}
+
+	return nil
+}
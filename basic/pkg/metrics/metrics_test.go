@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterBuildInfo(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	td.CmpNoError(t, RegisterBuildInfo(reg, "main", "abc", "2024-01-01T00:00:00Z"))
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP build_info Version of the binary, the value is always 1.
+# TYPE build_info gauge
+build_info{branch="main",build_time="2024-01-01T00:00:00Z",commit="abc"} 1
+`), "build_info")
+	td.CmpNoError(t, err)
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so no database is required.
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost:1/test?pool_max_conns=4")
+	td.CmpNoError(t, err)
+	t.Cleanup(pool.Close)
+
+	reg := prometheus.NewRegistry()
+	td.CmpNoError(t, reg.Register(NewPoolCollector(pool)))
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP pgxpool_acquired_conns Number of currently acquired connections in the pool.
+# TYPE pgxpool_acquired_conns gauge
+pgxpool_acquired_conns 0
+# HELP pgxpool_max_conns Maximum size of the pool.
+# TYPE pgxpool_max_conns gauge
+pgxpool_max_conns 4
+`), "pgxpool_acquired_conns", "pgxpool_max_conns")
+	td.CmpNoError(t, err)
+
+	td.Cmp(t, testutil.CollectAndCount(NewPoolCollector(pool)), 12)
+}
+
+func TestPGXTracer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	next := &recordTracer{}
+	tracer := NewPGXTracer(reg, next)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL: "-- name: GetCat\nSELECT id FROM cat WHERE id = $1;",
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("conn closed")})
+
+	td.Cmp(t, testutil.CollectAndCount(reg, "pgx_query_duration_seconds"), 2)
+	td.Cmp(t, next.calls, []string{"start", "end", "start", "end"})
+
+	// Series of other labels would be created by the lookups.
+	for _, labels := range [][]string{{"GetCat", "ok"}, {"SELECT", "error"}} {
+		_, err := tracer.duration.GetMetricWithLabelValues(labels...)
+		td.CmpNoError(t, err)
+	}
+
+	td.Cmp(t, testutil.CollectAndCount(reg, "pgx_query_duration_seconds"), 2)
+}
+
+func TestStatementName(t *testing.T) {
+	tests := map[string]string{
+		"-- name: CreateCat\nINSERT INTO cat VALUES ($1);": "CreateCat",
+		"  select 1;":               "SELECT",
+		"-- name:\nDELETE FROM cat": "DELETE",
+		"":                          "QUERY",
+	}
+
+	for sql, want := range tests {
+		td.Cmp(t, statementName(sql), want, sql)
+	}
+}
+
+type recordTracer struct {
+	calls []string
+}
+
+func (r *recordTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	r.calls = append(r.calls, "start")
+	return ctx
+}
+
+func (r *recordTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {
+	r.calls = append(r.calls, "end")
+}
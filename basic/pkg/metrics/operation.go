@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+)
+
+type operationKey struct{}
+
+// operation holds the operation label of a request, it is set by the handler
+// while the metrics are observed by a middleware after the handler returns.
+type operation struct {
+	mu   sync.Mutex
+	name string
+}
+
+// ContextWithOperation returns a copy of ctx, which holds the operation label
+// of the request, see SetOperation.
+func ContextWithOperation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operationKey{}, &operation{})
+}
+
+// SetOperation sets the operation label of the request, e.g. the GraphQL operation,
+// so requests to a single route are told apart by the metrics. The values of the
+// label must be bounded, e.g. by the schema. Does nothing if ctx holds no label.
+func SetOperation(ctx context.Context, name string) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return
+	}
+
+	op.mu.Lock()
+	op.name = name
+	op.mu.Unlock()
+}
+
+// Operation returns the operation label of the request, empty if it is not set.
+func Operation(ctx context.Context) string {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return ""
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	return op.name
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ pgx.QueryTracer = (*PGXTracer)(nil)
+)
+
+// statementNamePrefix starts a comment which names the statement, e.g. "-- name: GetCat".
+const statementNamePrefix = "-- name:"
+
+type queryStartKey struct{}
+
+// PGXTracer implements pgx.QueryTracer by a histogram of query durations
+// labelled with the statement name and the status, ok or error.
+// Statements are named by a leading "-- name: <Name>" comment,
+// unnamed ones by their first keyword, e.g. SELECT.
+type PGXTracer struct {
+	next     pgx.QueryTracer
+	duration *prometheus.HistogramVec
+}
+
+// NewPGXTracer returns a pointer to a new instance of PGXTracer.
+// Its metrics are registered in reg, the given next tracer,
+// e.g. tracing.PGXTracer, is called around each query if not nil.
+func NewPGXTracer(reg prometheus.Registerer, next pgx.QueryTracer) *PGXTracer {
+	t := PGXTracer{
+		next: next,
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pgx_query_duration_seconds",
+			Help:    "Duration of Postgres queries.",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		}, []string{"statement", "status"}),
+	}
+
+	return &t
+}
+
+type queryStart struct {
+	at        time.Time
+	statement string
+}
+
+func (t *PGXTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if t.next != nil {
+		ctx = t.next.TraceQueryStart(ctx, conn, data)
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: time.Now(), statement: statementName(data.SQL)})
+}
+
+func (t *PGXTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if start, ok := ctx.Value(queryStartKey{}).(queryStart); ok {
+		status := "ok"
+		if data.Err != nil {
+			status = "error"
+		}
+
+		t.duration.WithLabelValues(start.statement, status).Observe(time.Since(start.at).Seconds())
+	}
+
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// statementName returns the name given by the leading comment of the statement,
+// otherwise its first keyword after the comments, so the label has a bounded number of values.
+func statementName(sql string) string {
+	sql = strings.TrimSpace(sql)
+
+	if rest, ok := strings.CutPrefix(sql, statementNamePrefix); ok {
+		name, _, _ := strings.Cut(rest, "\n")
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+
+	for strings.HasPrefix(sql, "--") {
+		_, sql, _ = strings.Cut(sql, "\n")
+		sql = strings.TrimSpace(sql)
+	}
+
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+
+	return strings.ToUpper(strings.TrimSuffix(fields[0], ";"))
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ prometheus.Collector = (*PoolCollector)(nil)
+)
+
+// PoolStater provides statistics of a connection pool, e.g. pgxpool.Pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+var (
+	poolAcquiredConnsDesc = prometheus.NewDesc("pgxpool_acquired_conns",
+		"Number of currently acquired connections in the pool.", nil, nil)
+	poolIdleConnsDesc = prometheus.NewDesc("pgxpool_idle_conns",
+		"Number of currently idle connections in the pool.", nil, nil)
+	poolConstructingConnsDesc = prometheus.NewDesc("pgxpool_constructing_conns",
+		"Number of connections with construction in progress in the pool.", nil, nil)
+	poolTotalConnsDesc = prometheus.NewDesc("pgxpool_total_conns",
+		"Total number of resources currently in the pool.", nil, nil)
+	poolMaxConnsDesc = prometheus.NewDesc("pgxpool_max_conns",
+		"Maximum size of the pool.", nil, nil)
+	poolAcquiresDesc = prometheus.NewDesc("pgxpool_acquires_total",
+		"Number of successful acquires from the pool.", nil, nil)
+	poolAcquireDurationDesc = prometheus.NewDesc("pgxpool_acquire_duration_seconds_total",
+		"Total duration of all successful acquires from the pool.", nil, nil)
+	poolEmptyAcquiresDesc = prometheus.NewDesc("pgxpool_empty_acquires_total",
+		"Number of successful acquires which waited for a connection, because the pool was empty.", nil, nil)
+	poolCanceledAcquiresDesc = prometheus.NewDesc("pgxpool_canceled_acquires_total",
+		"Number of acquires from the pool which were canceled by a context.", nil, nil)
+	poolNewConnsDesc = prometheus.NewDesc("pgxpool_new_conns_total",
+		"Number of new connections opened by the pool.", nil, nil)
+	poolMaxLifetimeDestroysDesc = prometheus.NewDesc("pgxpool_max_lifetime_destroys_total",
+		"Number of connections destroyed because they exceeded the max lifetime.", nil, nil)
+	poolMaxIdleDestroysDesc = prometheus.NewDesc("pgxpool_max_idle_destroys_total",
+		"Number of connections destroyed because they exceeded the max idle time.", nil, nil)
+)
+
+// PoolCollector implements prometheus.Collector by the statistics of a pgx pool,
+// which are taken on every scrape.
+type PoolCollector struct {
+	pool PoolStater
+}
+
+// NewPoolCollector returns a pointer to a new instance of PoolCollector.
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	c := PoolCollector{pool: pool}
+
+	return &c
+}
+
+func (*PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConnsDesc
+	ch <- poolIdleConnsDesc
+	ch <- poolConstructingConnsDesc
+	ch <- poolTotalConnsDesc
+	ch <- poolMaxConnsDesc
+	ch <- poolAcquiresDesc
+	ch <- poolAcquireDurationDesc
+	ch <- poolEmptyAcquiresDesc
+	ch <- poolCanceledAcquiresDesc
+	ch <- poolNewConnsDesc
+	ch <- poolMaxLifetimeDestroysDesc
+	ch <- poolMaxIdleDestroysDesc
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConnsDesc, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConnsDesc, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolConstructingConnsDesc, prometheus.GaugeValue,
+		float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConnsDesc, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConnsDesc, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue,
+		stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquiresDesc, prometheus.CounterValue,
+		float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquiresDesc, prometheus.CounterValue,
+		float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolNewConnsDesc, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(poolMaxLifetimeDestroysDesc, prometheus.CounterValue,
+		float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(poolMaxIdleDestroysDesc, prometheus.CounterValue,
+		float64(stat.MaxIdleDestroyCount()))
+}
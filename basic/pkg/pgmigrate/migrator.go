@@ -6,12 +6,15 @@ import (
 	"io/fs"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/tern/v2/migrate"
 )
 
-// New returns a pointer to a new instance of Migrator.
+// versionTable is the table holding the version of the applied migrations.
+const versionTable = "migration"
+
+// New returns a pointer to a new instance of Migrator, which holds a connection
+// acquired from conn until Close is called.
 func New(conn *pgxpool.Pool, migrations fs.FS) (*Migrator, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -21,31 +24,57 @@ func New(conn *pgxpool.Pool, migrations fs.FS) (*Migrator, error) {
 		return nil, fmt.Errorf("failed to acquire connection for migrator: %w", mConnErr)
 	}
 
-	m, mErr := migrate.NewMigratorEx(ctx, mConn.Conn(), "migration", &migrate.MigratorOptions{})
+	m, mErr := migrate.NewMigratorEx(ctx, mConn.Conn(), versionTable, &migrate.MigratorOptions{})
 	if mErr != nil {
+		mConn.Release()
 		return nil, fmt.Errorf("failed to create migrator: %w", mErr)
 	}
 
 	if err := m.LoadMigrations(migrations); err != nil {
+		mConn.Release()
 		return nil, fmt.Errorf("failed to load migrations: %w", err)
 	}
 
-	return &Migrator{conn: mConn.Conn(), m: m}, nil
+	return &Migrator{conn: mConn, m: m}, nil
 }
 
 // Migrator holds logic of how to load and apply database
 // migrations to Postgres.
 type Migrator struct {
-	conn *pgx.Conn
+	conn *pgxpool.Conn
 	m    *migrate.Migrator
 }
 
+// Close closes the connection of the migrator and returns it to the pool,
+// which destroys it, otherwise closing the pool blocks forever.
 func (m *Migrator) Close() error {
+	defer m.conn.Release()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	return m.conn.Close(ctx)
+	return m.conn.Conn().Close(ctx)
 }
 
 // Migrate performs migration of database schema.
 func (m *Migrator) Migrate(ctx context.Context) error { return m.m.Migrate(ctx) }
+
+// CheckVersion returns an error if the applied migrations of the database
+// are behind the given ones, e.g. to report the app as not ready.
+func CheckVersion(ctx context.Context, conn *pgxpool.Pool, migrations fs.FS) error {
+	files, err := migrate.FindMigrations(migrations)
+	if err != nil {
+		return fmt.Errorf("failed to find migrations: %w", err)
+	}
+
+	var current int32
+	if err := conn.QueryRow(ctx, "SELECT version FROM "+versionTable).Scan(&current); err != nil {
+		return fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	if int(current) < len(files) {
+		return fmt.Errorf("migration version %d is behind %d", current, len(files))
+	}
+
+	return nil
+}
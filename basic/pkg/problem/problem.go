@@ -0,0 +1,80 @@
+// Package problem implements RFC 7807 problem details
+// for reporting errors in HTTP API responses.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+)
+
+// ContentType is the media type of the problem details document.
+const ContentType = "application/problem+json"
+
+// Details represents a problem details document.
+// More about problem details: https://www.rfc-editor.org/rfc/rfc7807
+type Details struct {
+	Type     string  `json:"type,omitempty"`
+	Title    string  `json:"title"`
+	Status   int     `json:"status"`
+	Detail   string  `json:"detail,omitempty"`
+	Instance string  `json:"instance,omitempty"`
+	Errors   []Error `json:"errors,omitempty"`
+
+	// RequestID is the ID of the request, which clients report to match the logs of it.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error describes a single invalid part of the request.
+type Error struct {
+	// In is the location of the invalid part: path, query, header or body.
+	In string `json:"in,omitempty"`
+
+	// Name is the name of the invalid parameter.
+	Name string `json:"name,omitempty"`
+
+	// Reason describes why the part is invalid.
+	Reason string `json:"reason"`
+}
+
+// New returns a pointer to a new instance of Details
+// with the title derived from the given status code.
+func New(status int, detail string) *Details {
+	d := Details{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+
+	return &d
+}
+
+// Error implements error interface, so Details can be returned
+// and wrapped as a regular error.
+func (d *Details) Error() string {
+	if d.Detail == "" {
+		return d.Title
+	}
+
+	return d.Title + ": " + d.Detail
+}
+
+// Write writes the given problem details to w with the corresponding status code.
+func Write(w http.ResponseWriter, d *Details) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+
+	return json.NewEncoder(w).Encode(d)
+}
+
+// WriteRequest writes problem details of the failed request r to w with the given status code.
+// The instance is the path of the request, and the request ID is taken from its context.
+func WriteRequest(w http.ResponseWriter, r *http.Request, status int, detail string) error {
+	d := New(status, detail)
+	d.Instance = r.URL.Path
+	d.RequestID, _ = requestid.FromContext(r.Context())
+
+	return Write(w, d)
+}
@@ -0,0 +1,180 @@
+// Package ratelimit implements token bucket rate limiting
+// with limits per route and buckets per client.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+// ErrInvalidLimit indicates that a limit or a route rule can not be parsed.
+const ErrInvalidLimit xerr.Error = "ratelimit: invalid limit"
+
+// Limit allows Requests per Period on average, a bucket holds up to Requests tokens,
+// so an idle client may burst all of them at once. Zero limit means no limit.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit in form of <requests>/<period>, e.g. 100/1m.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%w: '%s' is not in form of <requests>/<period>", ErrInvalidLimit, s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("%w: '%s' requests must be a non negative number", ErrInvalidLimit, s)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("%w: '%s' period must be a positive duration", ErrInvalidLimit, s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// IsZero reports whether l imposes no limit.
+func (l Limit) IsZero() bool { return l.Requests == 0 }
+
+// String returns the limit in the form ParseLimit accepts.
+func (l Limit) String() string { return strconv.Itoa(l.Requests) + "/" + l.Period.String() }
+
+// Result describes the state of a bucket after taking a token from it.
+type Result struct {
+	// Allowed reports whether the token is taken and the request may proceed.
+	Allowed bool
+
+	// Remaining is the number of requests the client may send right away.
+	Remaining int
+
+	// Reset is the time left until the bucket is full again.
+	Reset time.Duration
+
+	// RetryAfter is the time left until the next token, zero if the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Store holds the buckets. MemoryStore is enough for a single instance,
+// instances sharing the quota need a shared backend, e.g. Redis.
+type Store interface {
+	// Take takes a token from the bucket of the given key, which is refilled at the given limit.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Route sets the Limit of requests with the Method, * matches any, and the path starting with the Prefix.
+type Route struct {
+	Method string
+	Prefix string
+	Limit  Limit
+}
+
+// ParseRoute parses a route rule in form of [<method> ]<path prefix>=<limit>,
+// e.g. POST /v1/cat=10/1m.
+func ParseRoute(s string) (Route, error) {
+	pattern, limit, ok := strings.Cut(s, "=")
+	if !ok {
+		return Route{}, fmt.Errorf("%w: '%s' is not in form of [<method> ]<path prefix>=<limit>", ErrInvalidLimit, s)
+	}
+
+	r := Route{Method: "*", Prefix: strings.TrimSpace(pattern)}
+	if method, prefix, ok := strings.Cut(r.Prefix, " "); ok {
+		r.Method, r.Prefix = strings.ToUpper(method), strings.TrimSpace(prefix)
+	}
+
+	if !strings.HasPrefix(r.Prefix, "/") {
+		return Route{}, fmt.Errorf("%w: '%s' path prefix must start with /", ErrInvalidLimit, s)
+	}
+
+	l, err := ParseLimit(limit)
+	if err != nil {
+		return Route{}, err
+	}
+
+	r.Limit = l
+
+	return r, nil
+}
+
+// String returns the route in the form ParseRoute accepts without the limit.
+func (r Route) String() string { return r.Method + " " + r.Prefix }
+
+// Compilation time checks for interface implementation.
+var (
+	_ Matcher = Rules{}
+	_ Matcher = (*DynamicRules)(nil)
+)
+
+// Matcher picks the Limit of a request.
+type Matcher interface {
+	// Match returns the name of the matched rule, which separates the buckets
+	// of different routes, and its limit.
+	Match(method, path string) (string, Limit)
+}
+
+// Rules picks the Limit of a request.
+type Rules struct {
+	// Default applies to requests which match none of the Routes.
+	Default Limit
+
+	// Routes override the Default, the longest matching prefix wins.
+	Routes []Route
+}
+
+// Match returns the name of the matched rule, which separates the buckets
+// of different routes, and its limit.
+func (rs Rules) Match(method, path string) (string, Limit) {
+	name, limit, matched := "default", rs.Default, -1
+
+	for _, r := range rs.Routes {
+		if r.Method != "*" && r.Method != method {
+			continue
+		}
+
+		if !strings.HasPrefix(path, r.Prefix) || len(r.Prefix) < matched {
+			continue
+		}
+
+		// A method specific rule wins over a wildcard one with the same prefix.
+		if len(r.Prefix) == matched && r.Method == "*" {
+			continue
+		}
+
+		name, limit, matched = r.String(), r.Limit, len(r.Prefix)
+	}
+
+	return name, limit
+}
+
+// DynamicRules holds Rules which may be replaced while requests are matched,
+// e.g. on a configuration reload. Buckets of the clients are kept.
+type DynamicRules struct {
+	rules atomic.Pointer[Rules]
+}
+
+// NewDynamicRules returns a pointer to a new instance of DynamicRules holding the rules.
+func NewDynamicRules(rules Rules) *DynamicRules {
+	d := DynamicRules{}
+	d.Set(rules)
+
+	return &d
+}
+
+// Set replaces the rules, it is safe to call concurrently with Match.
+func (d *DynamicRules) Set(rules Rules) {
+	d.rules.Store(&rules)
+}
+
+// Match matches the request by the current rules.
+func (d *DynamicRules) Match(method, path string) (string, Limit) {
+	return d.rules.Load().Match(method, path)
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestParseRoute(t *testing.T) {
+	type tcase struct {
+		in string
+
+		want    Route
+		wantErr error
+	}
+
+	tests := map[string]tcase{
+		"Method": {
+			in:   "post /v1/cat=10/1m",
+			want: Route{Method: "POST", Prefix: "/v1/cat", Limit: Limit{Requests: 10, Period: time.Minute}},
+		},
+		"Any method": {
+			in:   "/v1/cat=0/1s",
+			want: Route{Method: "*", Prefix: "/v1/cat", Limit: Limit{Requests: 0, Period: time.Second}},
+		},
+		"No limit": {
+			in:      "/v1/cat",
+			wantErr: ErrInvalidLimit,
+		},
+		"Relative path": {
+			in:      "GET v1/cat=1/1s",
+			wantErr: ErrInvalidLimit,
+		},
+		"Bad period": {
+			in:      "/v1/cat=1/0s",
+			wantErr: ErrInvalidLimit,
+		},
+		"Bad requests": {
+			in:      "/v1/cat=-1/1s",
+			wantErr: ErrInvalidLimit,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseRoute(tc.in)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tc.want)
+		})
+	}
+}
+
+func TestRules_Match(t *testing.T) {
+	rules := Rules{
+		Default: Limit{Requests: 100, Period: time.Minute},
+		Routes: []Route{
+			{Method: "*", Prefix: "/v1/cat", Limit: Limit{Requests: 50, Period: time.Minute}},
+			{Method: "POST", Prefix: "/v1/cat", Limit: Limit{Requests: 10, Period: time.Minute}},
+			{Method: "*", Prefix: "/v1/cat/export", Limit: Limit{Requests: 1, Period: time.Minute}},
+		},
+	}
+
+	type tcase struct {
+		method, path string
+
+		wantName     string
+		wantRequests int
+	}
+
+	tests := map[string]tcase{
+		"Default":         {method: "GET", path: "/v1/dog", wantName: "default", wantRequests: 100},
+		"Wildcard method": {method: "GET", path: "/v1/cat/1", wantName: "* /v1/cat", wantRequests: 50},
+		"Method":          {method: "POST", path: "/v1/cat", wantName: "POST /v1/cat", wantRequests: 10},
+		"Longest prefix":  {method: "POST", path: "/v1/cat/export", wantName: "* /v1/cat/export", wantRequests: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotName, gotLimit := rules.Match(tc.method, tc.path)
+			td.Cmp(t, gotName, tc.wantName)
+			td.Cmp(t, gotLimit.Requests, tc.wantRequests)
+		})
+	}
+}
+
+func TestDynamicRules(t *testing.T) {
+	rules := NewDynamicRules(Rules{Default: Limit{Requests: 100, Period: time.Minute}})
+
+	name, limit := rules.Match("GET", "/v1/cat")
+	td.Cmp(t, name, "default")
+	td.Cmp(t, limit.Requests, 100)
+
+	rules.Set(Rules{
+		Default: Limit{Requests: 100, Period: time.Minute},
+		Routes:  []Route{{Method: "*", Prefix: "/v1/cat", Limit: Limit{Requests: 5, Period: time.Minute}}},
+	})
+
+	name, limit = rules.Match("GET", "/v1/cat")
+	td.Cmp(t, name, "* /v1/cat")
+	td.Cmp(t, limit.Requests, 5)
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	s := NewMemoryStore()
+
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	take := func(key string) Result {
+		res, err := s.Take(context.Background(), key, limit)
+		td.CmpNoError(t, err)
+
+		return res
+	}
+
+	td.Cmp(t, take("a"), Result{Allowed: true, Remaining: 1, Reset: time.Second})
+	td.Cmp(t, take("a"), Result{Allowed: true, Remaining: 0, Reset: 2 * time.Second})
+	td.Cmp(t, take("a"), Result{Allowed: false, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second})
+
+	// Buckets of clients are independent.
+	td.Cmp(t, take("b").Allowed, true)
+
+	// A token is refilled every second.
+	now = now.Add(time.Second)
+	td.Cmp(t, take("a"), Result{Allowed: true, Remaining: 0, Reset: 2 * time.Second})
+
+	// Full buckets are dropped.
+	now = now.Add(sweepInterval)
+	take("c")
+	td.Cmp(t, len(s.buckets), 1)
+}
@@ -0,0 +1,62 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ pgx.QueryTracer = (*PGXTracer)(nil)
+)
+
+// PGXTracer implements pgx.QueryTracer by setting the application_name of the connection
+// to "<base> <request ID>" before a query if ctx holds a request ID, and back to base otherwise,
+// so queries of a request are found in pg_stat_activity and in the Postgres logs.
+// The name is set only if it differs from the current one, which the server reports,
+// so connections acquired by requests without queries cost no round trip.
+// Postgres truncates names longer than 63 bytes.
+type PGXTracer struct {
+	base string
+	next pgx.QueryTracer
+}
+
+// NewPGXTracer returns a pointer to a new instance of PGXTracer. The given
+// next tracer, e.g. metrics.PGXTracer, is called around each query if not nil.
+func NewPGXTracer(base string, next pgx.QueryTracer) *PGXTracer {
+	t := PGXTracer{
+		base: base,
+		next: next,
+	}
+
+	return &t
+}
+
+func (t *PGXTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := t.base
+	if id, ok := FromContext(ctx); ok {
+		name = t.base + " " + id
+	}
+
+	if conn.PgConn().ParameterStatus("application_name") != name {
+		// The name is set by the connection itself, so it is not traced. It outlives
+		// a cancelled request, and a failure keeps the previous name, the query runs anyway.
+		conn.PgConn().ExecParams( //nolint: errcheck
+			context.WithoutCancel(ctx), `SELECT set_config('application_name', $1, false);`,
+			[][]byte{[]byte(name)}, nil, nil, nil,
+		).Close()
+	}
+
+	if t.next != nil {
+		ctx = t.next.TraceQueryStart(ctx, conn, data)
+	}
+
+	return ctx
+}
+
+func (t *PGXTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.next != nil {
+		t.next.TraceQueryEnd(ctx, conn, data)
+	}
+}
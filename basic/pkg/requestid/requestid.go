@@ -0,0 +1,37 @@
+// Package requestid correlates the work done for a request across the logs,
+// the responses and the database sessions by a request ID.
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/idkit"
+)
+
+// Header is the HTTP header and the gRPC metadata key carrying the request ID.
+const Header = "X-Request-ID"
+
+// validID limits IDs given by clients, so they are safe to log and to put into
+// the application_name of database sessions, e.g. ULID or UUID.
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// New returns a new request ID.
+func New() string { return idkit.ULID() }
+
+// Valid reports whether the given ID, e.g. given by a client, can be used as a request ID.
+func Valid(id string) bool { return validID.MatchString(id) }
+
+type idCtxKey struct{}
+
+// ContextWithID returns a copy of ctx holding the given request ID.
+func ContextWithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idCtxKey{}, id)
+}
+
+// FromContext returns the request ID held by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idCtxKey{}).(string)
+
+	return id, ok && id != ""
+}
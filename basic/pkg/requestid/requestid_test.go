@@ -0,0 +1,35 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/requestid"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestValid(t *testing.T) {
+	tests := map[string]bool{
+		requestid.New():                        true,
+		"0b6f6a3e-62b0-4bd5-9f0a-2b9d1c1f6b2e": true,
+		"client.request:42":                    true,
+		"":                                     false,
+		strings.Repeat("a", 65):                false,
+		"with space":                           false,
+		"quote'":                               false,
+	}
+
+	for id, want := range tests {
+		td.Cmp(t, requestid.Valid(id), want, id)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := requestid.FromContext(context.Background())
+	td.CmpFalse(t, ok)
+
+	id, ok := requestid.FromContext(requestid.ContextWithID(context.Background(), "req-1"))
+	td.CmpTrue(t, ok)
+	td.Cmp(t, id, "req-1")
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgpassfile"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PassfileProvider provides the password of a database connection from a PostgreSQL
+// password file, see PGPASSFILE. The file is read on every call, so changes are picked up at once.
+type PassfileProvider struct {
+	path                       string
+	host, port, database, user string
+}
+
+// NewPassfileProvider returns a pointer to a new instance of PassfileProvider,
+// which looks up the password of the connection to the first host of cfg.
+func NewPassfileProvider(path string, cfg *pgconn.Config) *PassfileProvider {
+	host := cfg.Host
+	if strings.HasPrefix(host, "/") {
+		// Unix sockets are matched by localhost like libpq does.
+		host = "localhost"
+	}
+
+	p := PassfileProvider{
+		path:     path,
+		host:     host,
+		port:     strconv.Itoa(int(cfg.Port)),
+		database: cfg.Database,
+		user:     cfg.User,
+	}
+
+	return &p
+}
+
+// Secret returns the password of the first matching line of the file, empty if no line matches.
+func (p *PassfileProvider) Secret(context.Context) (string, error) {
+	passfile, err := pgpassfile.ReadPassfile(p.path)
+	if err != nil {
+		return "", fmt.Errorf("secrets: read passfile '%s': %w", p.path, err)
+	}
+
+	return passfile.FindPassword(p.host, p.port, p.database, p.user), nil
+}
+
+// BeforeConnect returns the pgxpool.Config.BeforeConnect hook, which sets the password
+// provided by p to every new connection, so rotated passwords are used without a restart.
+// Open connections are not affected, they are replaced after pgxpool.Config.MaxConnLifetime.
+// An empty password keeps the one of the connection string.
+func BeforeConnect(p Provider) func(ctx context.Context, cfg *pgx.ConnConfig) error {
+	return func(ctx context.Context, cfg *pgx.ConnConfig) error {
+		password, err := p.Secret(ctx)
+		if err != nil {
+			return fmt.Errorf("get database password: %w", err)
+		}
+
+		if password != "" {
+			cfg.Password = password
+		}
+
+		return nil
+	}
+}
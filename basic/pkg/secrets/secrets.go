@@ -0,0 +1,113 @@
+// Package secrets provides secrets which may rotate while the app runs,
+// e.g. database passwords mounted from Kubernetes secrets or a vault agent.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/log"
+)
+
+// DefaultReloadInterval is the default interval of checking secret files for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// Provider provides the current value of a secret. Secret is called every time
+// the secret is used, so providers of remote secrets should cache them.
+type Provider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// Static is a secret which never changes.
+type Static string
+
+// Secret returns the secret itself.
+func (s Static) Secret(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// FileProvider provides a secret read from a file, which is reloaded on change.
+type FileProvider struct {
+	path string
+
+	mu     sync.RWMutex
+	secret string
+}
+
+// NewFileProvider returns a pointer to a new instance of FileProvider with the secret read from path.
+func NewFileProvider(path string) (*FileProvider, error) {
+	p := FileProvider{path: path}
+
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Secret returns the secret read by the latest successful reload.
+func (p *FileProvider) Secret(context.Context) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.secret, nil
+}
+
+// Reload reads the secret from the file, reports whether it has changed since the last reload.
+// The secret in use is kept if the file can not be read.
+func (p *FileProvider) Reload() (bool, error) {
+	secret, err := ReadFile(p.path)
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if secret == p.secret {
+		return false, nil
+	}
+
+	p.secret = secret
+
+	return true, nil
+}
+
+// Watch reloads the secret every interval until ctx is done.
+// Failures are logged, the secret in use is kept.
+func (p *FileProvider) Watch(ctx context.Context, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := p.Reload()
+			if err != nil {
+				logger.Errorf("Failed to reload secret: %s", err.Error())
+				continue
+			}
+
+			if reloaded {
+				logger.Infof("Secret reloaded from '%s'", p.path)
+			}
+		}
+	}
+}
+
+// ReadFile reads a secret from the file, trailing line breaks are trimmed,
+// since editors and `kubectl create secret --from-file` keep them.
+func ReadFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("secrets: read '%s': %w", path, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,100 @@
+// Package tenant holds the tenant of a request, which isolates the data
+// of customers served by one deployment.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/xerr"
+)
+
+const (
+	// Header is the header a caller not bound to a tenant selects the tenant with.
+	Header = "X-Tenant-ID"
+
+	// ErrMissing indicates that the tenant of a request can not be resolved.
+	ErrMissing xerr.Error = "tenant: missing tenant"
+
+	// ErrInvalid indicates that the resolved tenant id is malformed.
+	ErrInvalid xerr.Error = "tenant: invalid tenant"
+
+	// ErrMismatch indicates that the caller selects a tenant other than the one it is bound to,
+	// or selects a tenant without being granted CrossTenantScope.
+	ErrMismatch xerr.Error = "tenant: tenant mismatch"
+
+	// CrossTenantScope is the scope which allows principals not bound to a tenant,
+	// e.g. trusted services, to select the tenant by the Header.
+	CrossTenantScope = "tenant:any"
+)
+
+var idRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type tenantCtxKey struct{}
+
+// ContextWithID returns a copy of ctx holding the given tenant id.
+func ContextWithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, id)
+}
+
+// FromContext returns the tenant id held by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantCtxKey{}).(string)
+
+	return id, ok && id != ""
+}
+
+// Resolver resolves the tenant of a request.
+type Resolver struct {
+	defaultID string
+}
+
+// NewResolver returns a pointer to a new instance of Resolver.
+// The defaultID is used when neither the principal nor the header
+// carries a tenant, empty defaultID makes the tenant mandatory.
+func NewResolver(defaultID string) *Resolver {
+	r := Resolver{defaultID: defaultID}
+
+	return &r
+}
+
+// Resolve returns the tenant of the request with the given context and header.
+// The tenant of the principal in ctx wins, the Header may only repeat it.
+// Principals not bound to a tenant select the tenant by the Header
+// only if they are granted CrossTenantScope, e.g. trusted services.
+func (r *Resolver) Resolve(ctx context.Context, header http.Header) (string, error) {
+	requested := strings.TrimSpace(header.Get(Header))
+
+	id := requested
+
+	p, ok := auth.PrincipalFromContext(ctx)
+
+	switch {
+	case ok && p.Tenant != "":
+		if requested != "" && requested != p.Tenant {
+			return "", fmt.Errorf("%w: principal of tenant '%s' requested tenant '%s'", ErrMismatch, p.Tenant, requested)
+		}
+
+		id = p.Tenant
+	case requested != "" && !(ok && p.HasScope(CrossTenantScope)):
+		return "", fmt.Errorf("%w: principal without scope %s requested tenant '%s'", ErrMismatch, CrossTenantScope, requested)
+	}
+
+	if id == "" {
+		id = r.defaultID
+	}
+
+	if id == "" {
+		return "", ErrMissing
+	}
+
+	if !idRe.MatchString(id) {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalid, id)
+	}
+
+	return id, nil
+}
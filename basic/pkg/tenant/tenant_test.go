@@ -0,0 +1,105 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/auth"
+	"github.com/KitRUM/golang-blueprint/basicrest/pkg/tenant"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	type tcase struct {
+		defaultID string
+		principal *auth.Principal
+		header    string
+
+		want    string
+		wantErr error
+	}
+
+	tests := map[string]tcase{
+		"Principal tenant": {
+			principal: &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			want:      "acme",
+		},
+		"Principal tenant repeated in header": {
+			principal: &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			header:    "acme",
+			want:      "acme",
+		},
+		"Principal tenant wins over default": {
+			defaultID: "default",
+			principal: &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			want:      "acme",
+		},
+		"Header of principal without tenant": {
+			principal: &auth.Principal{Subject: "service", Scopes: []string{tenant.CrossTenantScope}},
+			header:    "globex",
+			want:      "globex",
+		},
+		"Header without cross-tenant scope": {
+			principal: &auth.Principal{Subject: "apikey:1", Scopes: []string{"cat:read"}},
+			header:    "globex",
+			wantErr:   tenant.ErrMismatch,
+		},
+		"Header without principal": {
+			header:  "globex",
+			wantErr: tenant.ErrMismatch,
+		},
+		"Default": {
+			defaultID: "default",
+			principal: &auth.Principal{Subject: "service"},
+			want:      "default",
+		},
+		"Header of other tenant": {
+			principal: &auth.Principal{Subject: "user-1", Tenant: "acme"},
+			header:    "globex",
+			wantErr:   tenant.ErrMismatch,
+		},
+		"Missing": {
+			principal: &auth.Principal{Subject: "service"},
+			wantErr:   tenant.ErrMissing,
+		},
+		"Invalid": {
+			principal: &auth.Principal{Subject: "service", Scopes: []string{tenant.CrossTenantScope}},
+			header:    "acme; drop table cat",
+			wantErr:   tenant.ErrInvalid,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = auth.ContextWithPrincipal(ctx, tc.principal)
+			}
+
+			header := http.Header{}
+			if tc.header != "" {
+				header.Set(tenant.Header, tc.header)
+			}
+
+			got, err := tenant.NewResolver(tc.defaultID).Resolve(ctx, header)
+			if tc.wantErr != nil {
+				td.Cmp(t, errors.Is(err, tc.wantErr), true, err)
+				return
+			}
+
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, tc.want)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := tenant.FromContext(context.Background())
+	td.Cmp(t, ok, false)
+
+	got, ok := tenant.FromContext(tenant.ContextWithID(context.Background(), "acme"))
+	td.Cmp(t, ok, true)
+	td.Cmp(t, got, "acme")
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Compilation time checks for interface implementation.
+var (
+	_ pgx.QueryTracer = (*PGXTracer)(nil)
+)
+
+// PGXTracer implements pgx.QueryTracer by a client span per query, e.g. set
+// as ConnConfig.Tracer of the pool. Arguments of the queries are not recorded.
+type PGXTracer struct{}
+
+// NewPGXTracer returns a pointer to a new instance of PGXTracer.
+func NewPGXTracer() *PGXTracer { return &PGXTracer{} }
+
+func (*PGXTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+
+	ctx, _ = Tracer().Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+			semconv.DBNamespace(conn.Config().Database),
+		),
+	)
+
+	return ctx
+}
+
+func (*PGXTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(attribute.Int64("db.response.rows_affected", data.CommandTag.RowsAffected()))
+	}
+
+	End(span, data.Err)
+}
+
+// sqlOperation returns the first keyword of the statement, e.g. SELECT.
+// Leading line comments, e.g. the statement name, are skipped.
+func sqlOperation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "--") {
+		_, sql, _ = strings.Cut(sql, "\n")
+		sql = strings.TrimSpace(sql)
+	}
+
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+
+	return strings.ToUpper(strings.TrimSuffix(fields[0], ";"))
+}
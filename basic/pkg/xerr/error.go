@@ -13,6 +13,14 @@ const (
 	// ErrAlreadyExists indicates an attempt to create an entity
 	// which is failed because such entity already exists.
 	ErrAlreadyExists Error = "already exists"
+
+	// ErrInvalidArgument indicates that the given input is malformed
+	// or does not satisfy the constraints of the operation.
+	ErrInvalidArgument Error = "invalid argument"
+
+	// ErrPermissionDenied indicates that the caller is not allowed
+	// to perform the operation.
+	ErrPermissionDenied Error = "permission denied"
 )
 
 // Error represents an package level xerr.
@@ -13,6 +13,8 @@ func TestError_Error(t *testing.T) {
 	tests := map[string]tcase{
 		"ErrNotFound":     {err: ErrNotFound, want: "not found"},
 		"ErrAlreadyExist": {err: ErrAlreadyExists, want: "already exists"},
+		"ErrInvalidArg":   {err: ErrInvalidArgument, want: "invalid argument"},
+		"ErrPermDenied":   {err: ErrPermissionDenied, want: "permission denied"},
 		"Custom":          {err: Error("test error"), want: "test error"},
 	}
 